@@ -31,6 +31,7 @@ import (
 
 	"github.com/perlin-network/wavelet-operator/pkg/apis"
 	"github.com/perlin-network/wavelet-operator/pkg/controller"
+	"github.com/perlin-network/wavelet-operator/pkg/webhook"
 
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
 	"github.com/operator-framework/operator-sdk/pkg/leader"
@@ -42,6 +43,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/signals"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 // Change below variables to serve metrics on different host or port.
@@ -49,6 +51,12 @@ var (
 	metricsHost       = "0.0.0.0"
 	metricsPort int32 = 8383
 )
+
+// Change below variables to serve admission webhooks on a different port, or to store their certificate elsewhere.
+var (
+	webhookPort    int32 = 9443
+	webhookCertDir       = "/tmp/wavelet-operator/cert"
+)
 var log = logf.Log.WithName("cmd")
 
 func printVersion() {
@@ -126,6 +134,19 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Setup all Webhooks. The webhook server is only reachable by the API server through a Service in the namespace
+	// the operator runs in, so webhooks are skipped should the operator be run outside of a cluster.
+	operatorNamespace, err := k8sutil.GetOperatorNamespace()
+	if err == k8sutil.ErrNoNamespace {
+		log.Info("Skipping admission webhooks; not running in a cluster.")
+	} else if err != nil {
+		log.Error(err, "Failed to get operator namespace")
+		os.Exit(1)
+	} else if err := webhook.AddToManager(mgr, getWebhookServerOptions(operatorNamespace)); err != nil {
+		log.Error(err, "")
+		os.Exit(1)
+	}
+
 	// Create Service object to expose the metrics port.
 	_, err = metrics.ExposeMetricsPort(ctx, metricsPort)
 	if err != nil {
@@ -140,3 +161,21 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// getWebhookServerOptions configures the webhook server to provision a self-signed certificate for itself, and to
+// install the webhook configurations and Service needed for the API server to reach it.
+func getWebhookServerOptions(namespace string) crwebhook.ServerOptions {
+	return crwebhook.ServerOptions{
+		Port:    webhookPort,
+		CertDir: webhookCertDir,
+		BootstrapOptions: &crwebhook.BootstrapOptions{
+			MutatingWebhookConfigName:   "wavelet-operator-mutating",
+			ValidatingWebhookConfigName: "wavelet-operator-validating",
+			Service: &crwebhook.Service{
+				Name:      "wavelet-operator-webhook",
+				Namespace: namespace,
+				Selectors: map[string]string{"name": "wavelet-operator"},
+			},
+		},
+	}
+}
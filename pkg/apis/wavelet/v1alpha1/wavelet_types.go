@@ -20,25 +20,557 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // WaveletSpec defines the desired state of Wavelet
 // +k8s:openapi-gen=true
 type WaveletSpec struct {
-	Size             int32 `json:"size"`
-	NumRichWallets   uint  `json:"num_rich_wallets"`
-	NumBenchmarkPods uint  `json:"num_benchmark_pods"`
+	// Size is the number of nodes in the cluster. All nodes are torn down should it be 0.
+	// +kubebuilder:validation:Minimum=0
+	Size int32 `json:"size"`
+
+	// NumSeeds is the number of nodes, starting from the bootstrap node, every node bootstraps to through their
+	// stable DNS names. Nodes keep being able to join the cluster for as long as any seed is up. It defaults to 1, and
+	// may not exceed Size unless Size is 0.
+	// +kubebuilder:validation:Minimum=1
+	NumSeeds int32 `json:"num_seeds,omitempty"`
+
+	// NumRichWallets is the number of wallets funded at genesis. The node with ordinal i is assigned the i-th rich
+	// wallet, and the bootstrap node the wallet built into its image.
+	// +kubebuilder:validation:Minimum=0
+	NumRichWallets uint `json:"num_rich_wallets"`
+
+	// NodeBalance is allocated at genesis to the wallet of every node that is not assigned a rich wallet. Nodes
+	// added after the cluster is created are never funded. It may not be changed once the cluster is created.
+	// +kubebuilder:validation:Minimum=0
+	NodeBalance uint64 `json:"node_balance,omitempty"`
+
+	// WalletSeed deterministically derives the keys of all wallets generated for the cluster, such that clusters
+	// sharing the same seed and genesis parameters share a byte-identical genesis. Wallets are generated from fresh
+	// randomness should it be left unset. It may not be changed once the cluster is created.
+	WalletSeed string `json:"wallet_seed,omitempty"`
+
+	// Puzzle configures the difficulty of the S/Kademlia puzzles that the keys of all nodes and wallets must solve.
+	// It may not be changed once the cluster is created.
+	Puzzle WaveletPuzzleSpec `json:"puzzle,omitempty"`
+
+	// Genesis configures additional state allocated at genesis. It may not be changed once the cluster is created.
+	Genesis *WaveletGenesisSpec `json:"genesis,omitempty"`
+
+	// NumBenchmarkPods is the number of benchmark pods run against the cluster for as long as it exists. Benchmarks
+	// with parameters and a lifecycle of their own are run through WaveletBenchmark instead.
+	// +kubebuilder:validation:Minimum=0
+	NumBenchmarkPods uint `json:"num_benchmark_pods"`
+
+	// BenchmarkTarget configures which nodes benchmark pods target, and which wallets they use. Benchmark pods are
+	// spread round-robin across all nodes, each using the wallet of the node it targets, should it be left unset.
+	BenchmarkTarget *WaveletBenchmarkTargetSpec `json:"benchmark_target,omitempty"`
+
+	// Image is the container image nodes are run with. It defaults to the latest build of Wavelet.
+	Image string `json:"image,omitempty"`
+
+	// BenchmarkImage is the container image benchmark pods are run with. It defaults to Image.
+	BenchmarkImage string `json:"benchmark_image,omitempty"`
+
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	ImagePullPolicy corev1.PullPolicy `json:"image_pull_policy,omitempty"`
+
+	// ImagePullSecrets defaults to a single secret named regcred.
+	ImagePullSecrets []corev1.LocalObjectReference `json:"image_pull_secrets,omitempty"`
+
+	Consensus WaveletConsensusSpec `json:"consensus,omitempty"`
+
+	// MemoryMax is the maximum amount of memory in MiB a node may use. It defaults to 4096.
+	// +kubebuilder:validation:Minimum=0
+	MemoryMax int32 `json:"memory_max,omitempty"`
+
+	// ExtraEnv is appended to the environment of every node, and takes precedence over variables set by the operator.
+	ExtraEnv []corev1.EnvVar `json:"extra_env,omitempty"`
+
+	// ExtraArgs are passed as flags to every node.
+	ExtraArgs []string `json:"extra_args,omitempty"`
+
+	UpdateStrategy WaveletUpdateStrategy `json:"update_strategy,omitempty"`
+
+	// Probes configures the readiness and liveness probes nodes are run with.
+	Probes WaveletProbesSpec `json:"probes,omitempty"`
+
+	// Health configures how the operator judges the ledger state of nodes it periodically queries.
+	Health WaveletHealthSpec `json:"health,omitempty"`
+
+	// Remediation has the operator recreate nodes that are stuck lagging behind, forked from or isolated from the
+	// rest of the cluster. Stuck nodes are left alone should it be unset.
+	Remediation *WaveletRemediationSpec `json:"remediation,omitempty"`
+
+	// Storage configures a persistent volume for the ledger database of each node. Nodes keep their ledger on the
+	// ephemeral filesystem of their container should it be left unset.
+	Storage *WaveletStorageSpec `json:"storage,omitempty"`
+
+	// Archive configures where the ledgers of nodes and the genesis and wallets of the cluster are archived to before
+	// the cluster is torn down. Nothing is archived should it be left unset.
+	Archive *WaveletArchiveSpec `json:"archive,omitempty"`
+}
+
+// WaveletPuzzleSpec defines the difficulty of the S/Kademlia static and dynamic puzzles
+// +k8s:openapi-gen=true
+type WaveletPuzzleSpec struct {
+	// C1 is the number of leading zero bits the checksum of a public key must have. It defaults to 16.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=32
+	C1 int32 `json:"c1,omitempty"`
+
+	// C2 is the number of leading zero bits the checksum of a public key xor'ed with a nonce must have. It defaults
+	// to 16.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=32
+	C2 int32 `json:"c2,omitempty"`
+}
+
+// WaveletGenesisSpec defines additional state allocated at genesis
+// +k8s:openapi-gen=true
+type WaveletGenesisSpec struct {
+	// Accounts are allocated at genesis on top of the rich wallets of the cluster.
+	Accounts []WaveletGenesisAccount `json:"accounts,omitempty"`
+
+	// ConfigMap points at a complete genesis JSON file that rich wallets and accounts are allocated on top of. It may
+	// describe any state nodes understand, such as contracts.
+	ConfigMap *WaveletGenesisConfigMapSource `json:"config_map,omitempty"`
+}
+
+// WaveletGenesisAccount defines an account allocated at genesis
+// +k8s:openapi-gen=true
+type WaveletGenesisAccount struct {
+	// PublicKey is the hex-encoded public key of the account. A wallet is generated for the account and stored in
+	// the wallet secret of the cluster should it be left unset.
+	// +kubebuilder:validation:Pattern=`^[0-9a-f]{64}$`
+	PublicKey string `json:"public_key,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	Balance uint64 `json:"balance,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	Stake uint64 `json:"stake,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	Reward uint64 `json:"reward,omitempty"`
+}
+
+// WaveletGenesisConfigMapSource selects a key of a ConfigMap holding a genesis JSON file
+// +k8s:openapi-gen=true
+type WaveletGenesisConfigMapSource struct {
+	// Name is the name of a ConfigMap in the namespace of the cluster.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Key defaults to genesis.json.
+	Key string `json:"key,omitempty"`
+}
+
+// WaveletConsensusSpec defines the Snowball consensus parameters nodes are run with
+// +k8s:openapi-gen=true
+type WaveletConsensusSpec struct {
+	// SnowballK is the number of peers sampled per query. It defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1024
+	SnowballK int32 `json:"snowball_k,omitempty"`
+
+	// SnowballAlpha is the fraction of sampled peers that must agree on a preference for a query to succeed. It
+	// defaults to the default of the nodes.
+	// +kubebuilder:validation:Pattern=`^(0(\.[0-9]+)?|1(\.0+)?)$`
+	SnowballAlpha string `json:"snowball_alpha,omitempty"`
+
+	// SnowballBeta is the number of consecutive successful queries required to finalize a round. It defaults to 20.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10000
+	SnowballBeta int32 `json:"snowball_beta,omitempty"`
+}
+
+// WaveletBenchmarkTargetStrategy describes how benchmark clients are spread across the nodes of a cluster.
+// +kubebuilder:validation:Enum=RoundRobin;Random;Bootstrap;Selector
+type WaveletBenchmarkTargetStrategy string
+
+const (
+	// WaveletBenchmarkTargetRoundRobin has the client with ordinal i target the node with ordinal i, wrapping
+	// around should there be more clients than nodes.
+	WaveletBenchmarkTargetRoundRobin WaveletBenchmarkTargetStrategy = "RoundRobin"
+
+	// WaveletBenchmarkTargetRandom has each client target a node picked at random. The node picked for a client is
+	// stable for as long as the size of the cluster does not change.
+	WaveletBenchmarkTargetRandom WaveletBenchmarkTargetStrategy = "Random"
+
+	// WaveletBenchmarkTargetBootstrap has every client target the bootstrap node.
+	WaveletBenchmarkTargetBootstrap WaveletBenchmarkTargetStrategy = "Bootstrap"
+
+	// WaveletBenchmarkTargetSelector spreads clients round-robin across the nodes whose pods match a label
+	// selector. Clients are reassigned as nodes start or stop matching the selector.
+	WaveletBenchmarkTargetSelector WaveletBenchmarkTargetStrategy = "Selector"
+)
+
+// WaveletBenchmarkWalletMode describes which wallets benchmark clients sign transactions with.
+// +kubebuilder:validation:Enum=Shared;Split
+type WaveletBenchmarkWalletMode string
+
+const (
+	// WaveletBenchmarkWalletsShared has each client use the wallet of the node it targets, such that all clients
+	// targeting the same node share a wallet.
+	WaveletBenchmarkWalletsShared WaveletBenchmarkWalletMode = "Shared"
+
+	// WaveletBenchmarkWalletsSplit has the client with ordinal i use the wallet of the node with ordinal i modulo
+	// the size of the cluster regardless of the node it targets, such that no two clients share a wallet unless there
+	// are more clients than nodes. Client 0, and every client whose ordinal is a multiple of the size of the cluster,
+	// therefore uses the wallet built into the image of the bootstrap node.
+	WaveletBenchmarkWalletsSplit WaveletBenchmarkWalletMode = "Split"
+)
+
+// WaveletBenchmarkTargetSpec configures which nodes benchmark clients submit transactions to, and which wallets
+// they sign them with
+// +k8s:openapi-gen=true
+type WaveletBenchmarkTargetSpec struct {
+	// Strategy defaults to RoundRobin.
+	Strategy WaveletBenchmarkTargetStrategy `json:"strategy,omitempty"`
+
+	// Selector selects the node pods targeted by the Selector strategy.
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Wallets defaults to Shared.
+	Wallets WaveletBenchmarkWalletMode `json:"wallets,omitempty"`
+}
+
+// WaveletProbesSpec configures how the kubelet probes the HTTP API of each node. A node is live and ready for as long
+// as its API responds. The API of wavelet has no endpoint that fails while a node is out of sync or lacks peers, so
+// those are judged by the health checks of the operator instead, as configured by WaveletHealthSpec.
+// +k8s:openapi-gen=true
+type WaveletProbesSpec struct {
+	// InitialDelaySeconds is the time a node is given to start before its liveness is probed. It defaults to 30.
+	// +kubebuilder:validation:Minimum=0
+	InitialDelaySeconds int32 `json:"initial_delay_seconds,omitempty"`
+
+	// PeriodSeconds is the interval at which nodes are probed. It defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds int32 `json:"period_seconds,omitempty"`
+
+	// FailureThreshold is the number of consecutive failed liveness probes after which a node is restarted. It
+	// defaults to 6.
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold int32 `json:"failure_threshold,omitempty"`
+}
+
+// WaveletHealthSpec configures when nodes are considered stuck, and when the cluster as a whole is considered
+// partitioned or stalled, based on the ledger state the operator queries from each node
+// +k8s:openapi-gen=true
+type WaveletHealthSpec struct {
+	// MaxRoundLag is the number of rounds a node may trail the median round of the cluster before it is considered
+	// to be lagging. It defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	MaxRoundLag int32 `json:"max_round_lag,omitempty"`
+
+	// MinPeers is the number of peers a node must be connected to in order to be healthy. Nodes of a cluster of a
+	// single node are exempt. It defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	MinPeers int32 `json:"min_peers,omitempty"`
+
+	// StuckSeconds is the time a node may lag, be out of sync, be isolated from its peers or have its API be
+	// unreachable before it is considered stuck. It is also the time the cluster may be partitioned before it is
+	// reported as such. It defaults to 120.
+	// +kubebuilder:validation:Minimum=1
+	StuckSeconds int32 `json:"stuck_seconds,omitempty"`
+
+	// StallSeconds is the time the median round of the cluster may not advance while benchmarks run against it
+	// before the cluster is considered stalled. It defaults to 300.
+	// +kubebuilder:validation:Minimum=1
+	StallSeconds int32 `json:"stall_seconds,omitempty"`
+}
+
+// WaveletRemediationSpec configures how often stuck nodes may be recreated
+// +k8s:openapi-gen=true
+type WaveletRemediationSpec struct {
+	// MaxPerHour is the maximum number of nodes recreated within any hour across the cluster. It defaults to 3.
+	// +kubebuilder:validation:Minimum=1
+	MaxPerHour int32 `json:"max_per_hour,omitempty"`
+
+	// BackoffSeconds is the time a node must wait after being recreated before it may be recreated again. It
+	// doubles with every time the node was recreated within the last hour, up to an hour. It defaults to 60.
+	// +kubebuilder:validation:Minimum=1
+	BackoffSeconds int32 `json:"backoff_seconds,omitempty"`
+
+	// WipeLedger deletes the ledger volume of a node alongside it, such that the node syncs its ledger from scratch.
+	// It has no effect unless the cluster is configured with persistent storage.
+	WipeLedger bool `json:"wipe_ledger,omitempty"`
+}
+
+// WaveletBootstrapOrder describes when the bootstrap node is replaced relative to all other nodes in a cluster.
+// +kubebuilder:validation:Enum=First;Last
+type WaveletBootstrapOrder string
+
+const (
+	// WaveletBootstrapFirst replaces the bootstrap node before all other nodes.
+	WaveletBootstrapFirst WaveletBootstrapOrder = "First"
+
+	// WaveletBootstrapLast replaces the bootstrap node after all other nodes.
+	WaveletBootstrapLast WaveletBootstrapOrder = "Last"
+)
+
+// WaveletUpdateStrategy configures how nodes are replaced once the spec they would be rendered with changes
+// +k8s:openapi-gen=true
+type WaveletUpdateStrategy struct {
+	// MaxUnavailable is the maximum number of nodes that may be unavailable while nodes are being replaced. It
+	// defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	MaxUnavailable int32 `json:"max_unavailable,omitempty"`
+
+	// BootstrapOrder defaults to Last.
+	BootstrapOrder WaveletBootstrapOrder `json:"bootstrap_order,omitempty"`
+}
+
+// WaveletStorageRetentionPolicy describes what happens to the ledger volumes of a cluster once it is deleted.
+// +kubebuilder:validation:Enum=Retain;Delete
+type WaveletStorageRetentionPolicy string
+
+const (
+	// WaveletStorageRetain keeps ledger volumes around after their cluster is deleted.
+	WaveletStorageRetain WaveletStorageRetentionPolicy = "Retain"
+
+	// WaveletStorageDelete garbage collects ledger volumes alongside their cluster.
+	WaveletStorageDelete WaveletStorageRetentionPolicy = "Delete"
+)
+
+// WaveletStorageSpec defines the persistent volume provisioned for the ledger database of each node
+// +k8s:openapi-gen=true
+type WaveletStorageSpec struct {
+	StorageClassName *string           `json:"storage_class,omitempty"`
+	Size             resource.Quantity `json:"size"`
+
+	// RetentionPolicy defaults to Retain.
+	RetentionPolicy WaveletStorageRetentionPolicy `json:"retention_policy,omitempty"`
+}
+
+// WaveletArchiveSpec defines the destination a cluster is archived to before it is torn down. Only the ledgers of
+// nodes with persistent storage are archived; the genesis and wallets of a cluster are always archived.
+// +k8s:openapi-gen=true
+type WaveletArchiveSpec struct {
+	// PersistentVolumeClaim is the name of an existing volume claim in the namespace of the cluster that archives
+	// are copied into.
+	PersistentVolumeClaim string `json:"persistent_volume_claim,omitempty"`
+
+	// S3 configures an S3-compatible object store, such as MinIO, that archives are uploaded to.
+	S3 *WaveletArchiveS3Spec `json:"s3,omitempty"`
+
+	// Image is the container image archive jobs are run with. It defaults to busybox when archiving to a volume
+	// claim, and to the MinIO client when archiving to an object store.
+	Image string `json:"image,omitempty"`
+}
+
+// WaveletArchiveS3Spec defines an S3-compatible object store archives are uploaded to
+// +k8s:openapi-gen=true
+type WaveletArchiveS3Spec struct {
+	// Endpoint is the URL of the object store, i.e. http://minio.default.svc:9000.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	Endpoint string `json:"endpoint"`
+
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+
+	// Prefix is prepended to the key of every archived object.
+	Prefix string `json:"prefix,omitempty"`
+
+	// CredentialsSecret is the name of a secret in the namespace of the cluster holding the access_key_id and
+	// secret_access_key used to authenticate against the object store.
+	// +kubebuilder:validation:MinLength=1
+	CredentialsSecret string `json:"credentials_secret"`
+}
+
+// WaveletWalletGenerationStatus reports how many of the wallets of a cluster have been generated
+// +k8s:openapi-gen=true
+type WaveletWalletGenerationStatus struct {
+	Generated int32 `json:"generated"`
+	Total     int32 `json:"total"`
+}
+
+// WaveletPhase is a coarse summary of where a Wavelet cluster is in its lifecycle.
+type WaveletPhase string
+
+const (
+	// WaveletPhaseBootstrapping means the bootstrap node is not yet up.
+	WaveletPhaseBootstrapping WaveletPhase = "Bootstrapping"
+
+	// WaveletPhaseScaling means nodes are being created, deleted or are not yet ready.
+	WaveletPhaseScaling WaveletPhase = "Scaling"
+
+	// WaveletPhaseBenchmarking means all nodes are ready and benchmark pods are running against them.
+	WaveletPhaseBenchmarking WaveletPhase = "Benchmarking"
+
+	// WaveletPhaseReady means all nodes are ready and no benchmark pods are requested.
+	WaveletPhaseReady WaveletPhase = "Ready"
+
+	// WaveletPhaseDegraded means the cluster cannot converge to its spec, or that its nodes are stuck, partitioned
+	// or stalled.
+	WaveletPhaseDegraded WaveletPhase = "Degraded"
+
+	// WaveletPhaseTerminating means the cluster is being archived and torn down.
+	WaveletPhaseTerminating WaveletPhase = "Terminating"
+)
+
+// WaveletConditionType is the type of a condition reported in WaveletStatus.
+type WaveletConditionType string
+
+const (
+	// WaveletConditionReady is true when every node in the cluster is ready.
+	WaveletConditionReady WaveletConditionType = "Ready"
+
+	// WaveletConditionProgressing is true while the cluster is converging to its spec.
+	WaveletConditionProgressing WaveletConditionType = "Progressing"
+
+	// WaveletConditionDegraded is true when the cluster cannot converge to its spec, or when its nodes are stuck,
+	// partitioned or stalled.
+	WaveletConditionDegraded WaveletConditionType = "Degraded"
+)
+
+// WaveletCondition describes the state of a Wavelet cluster at a certain point.
+// +k8s:openapi-gen=true
+type WaveletCondition struct {
+	Type               WaveletConditionType   `json:"type"`
+	Status             corev1.ConditionStatus `json:"status"`
+	ObservedGeneration int64                  `json:"observed_generation,omitempty"`
+	LastTransitionTime metav1.Time            `json:"last_transition_time,omitempty"`
+	Reason             string                 `json:"reason,omitempty"`
+	Message            string                 `json:"message,omitempty"`
 }
 
 // WaveletStatus defines the observed state of Wavelet
 // +k8s:openapi-gen=true
-type WaveletStatus struct{}
+type WaveletStatus struct {
+	ObservedGeneration int64        `json:"observed_generation,omitempty"`
+	Phase              WaveletPhase `json:"phase,omitempty"`
+
+	Nodes int32 `json:"nodes"`
+
+	// ReadyNodes is the number of nodes that are ready and were last judged healthy by the operator (see Health).
+	ReadyNodes    int32  `json:"ready_nodes"`
+	UpdatedNodes  int32  `json:"updated_nodes"`
+	BenchmarkPods int32  `json:"benchmark_pods"`
+	BootstrapIP   string `json:"bootstrap_ip,omitempty"`
+
+	// Seeds lists the addresses every node bootstraps to.
+	Seeds []string `json:"seeds,omitempty"`
+
+	// WalletGeneration reports the progress of generating the wallets of the cluster. It is only set while wallets
+	// are being generated.
+	WalletGeneration *WaveletWalletGenerationStatus `json:"wallet_generation,omitempty"`
+
+	// Wallets lists the wallet each node of the cluster is assigned, in order of their ordinal.
+	Wallets []WaveletNodeWalletStatus `json:"wallets,omitempty"`
+
+	// Round is the median round of the running nodes of the cluster, as last queried by the operator.
+	Round uint64 `json:"round,omitempty"`
+
+	// Health lists the ledger state of each running node of the cluster as last queried by the operator, in order of
+	// their ordinal.
+	Health []WaveletNodeHealthStatus `json:"health,omitempty"`
+
+	// Remediations lists the nodes recreated by the operator within the last hour, in the order they were recreated.
+	Remediations []WaveletRemediationStatus `json:"remediations,omitempty"`
+
+	Conditions []WaveletCondition `json:"conditions,omitempty"`
+}
+
+// WaveletNodeWalletStatus describes the wallet assigned to a node
+// +k8s:openapi-gen=true
+type WaveletNodeWalletStatus struct {
+	Node string `json:"node"`
+
+	// Wallet is the path to the wallet within the node container. It is empty until the wallet is generated.
+	Wallet string `json:"wallet,omitempty"`
+
+	// PublicKey is the hex-encoded public key of the wallet. It is unknown for the wallet built into the node image.
+	PublicKey string `json:"public_key,omitempty"`
+
+	// Funded reports whether the wallet was allocated a balance at genesis.
+	Funded bool `json:"funded"`
+}
+
+// WaveletNodeHealthState describes the ledger state of a node relative to the rest of its cluster.
+type WaveletNodeHealthState string
+
+const (
+	// WaveletNodeHealthy nodes are in sync, connected to enough peers and keep up with the median round of their
+	// cluster.
+	WaveletNodeHealthy WaveletNodeHealthState = "Healthy"
+
+	// WaveletNodeLagging nodes trail the median round of their cluster by more than the maximum round lag.
+	WaveletNodeLagging WaveletNodeHealthState = "Lagging"
+
+	// WaveletNodeForked nodes finalized the median round of their cluster with a different state than most nodes.
+	WaveletNodeForked WaveletNodeHealthState = "Forked"
+
+	// WaveletNodeIsolated nodes are connected to fewer peers than required despite being part of a cluster of
+	// multiple nodes.
+	WaveletNodeIsolated WaveletNodeHealthState = "Isolated"
+
+	// WaveletNodeOutOfSync nodes report their ledger to be out of sync with their peers.
+	WaveletNodeOutOfSync WaveletNodeHealthState = "OutOfSync"
+
+	// WaveletNodeUnreachable nodes did not respond to the operator querying their HTTP API.
+	WaveletNodeUnreachable WaveletNodeHealthState = "Unreachable"
+)
+
+// WaveletNodeHealthStatus describes the ledger state of a node as last queried by the operator
+// +k8s:openapi-gen=true
+type WaveletNodeHealthStatus struct {
+	Node  string                 `json:"node"`
+	State WaveletNodeHealthState `json:"state"`
+
+	// UID is the UID of the node pod the state was queried from, such that the state of a node is not mistaken for
+	// that of the pod replacing it.
+	UID types.UID `json:"uid,omitempty"`
+
+	// Stuck is set once the node has not been healthy for longer than the stuck threshold of the cluster.
+	Stuck bool `json:"stuck,omitempty"`
+
+	Round uint64 `json:"round"`
+	Peers int32  `json:"peers"`
+
+	// LastTransaction is the ID of the last transaction the node accepted.
+	LastTransaction string `json:"last_transaction,omitempty"`
+
+	// Since is the time the node entered its current state.
+	Since metav1.Time `json:"since"`
+
+	Message string `json:"message,omitempty"`
+}
+
+// WaveletRemediationStatus describes a node recreated by the operator
+// +k8s:openapi-gen=true
+type WaveletRemediationStatus struct {
+	Node string      `json:"node"`
+	Time metav1.Time `json:"time"`
+
+	// State is the state the node was stuck in.
+	State WaveletNodeHealthState `json:"state"`
+
+	// WipedLedger reports whether the ledger volume of the node was deleted alongside it.
+	WipedLedger bool `json:"wiped_ledger,omitempty"`
+
+	Message string `json:"message,omitempty"`
+}
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // Wavelet is the Schema for the wavelets API
 // +k8s:openapi-gen=true
+// +kubebuilder:resource:path=wavelets,singular=wavelet
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Size",type="integer",JSONPath=".spec.size"
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.ready_nodes"
+// +kubebuilder:printcolumn:name="Updated",type="integer",JSONPath=".status.updated_nodes"
+// +kubebuilder:printcolumn:name="Benchmarks",type="integer",JSONPath=".status.benchmark_pods"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Bootstrap",type="string",JSONPath=".status.bootstrap_ip"
+// +kubebuilder:printcolumn:name="Round",type="integer",JSONPath=".status.round",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 type Wavelet struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
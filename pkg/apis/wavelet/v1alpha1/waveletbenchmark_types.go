@@ -0,0 +1,273 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WaveletBenchmarkSpec defines the desired state of WaveletBenchmark
+// +k8s:openapi-gen=true
+type WaveletBenchmarkSpec struct {
+	// Cluster is the name of the Wavelet cluster in the same namespace the benchmark is run against.
+	// +kubebuilder:validation:MinLength=1
+	Cluster string `json:"cluster"`
+
+	// Workers is the number of benchmark clients run against the cluster. There may be more workers than there are
+	// nodes in the cluster.
+	// +kubebuilder:validation:Minimum=1
+	Workers int32 `json:"workers"`
+
+	// Target configures which nodes workers target, and which wallets they use. Workers are spread round-robin
+	// across all nodes, each using the wallet of the node it targets, should it be left unset.
+	Target *WaveletBenchmarkTargetSpec `json:"target,omitempty"`
+
+	// TargetTPS is the number of transactions per second the benchmark aims to submit across all workers, and is
+	// split evenly between them. Workers submit transactions as fast as they can should it be 0.
+	// +kubebuilder:validation:Minimum=0
+	TargetTPS uint32 `json:"target_tps,omitempty"`
+
+	// Duration is how long the benchmark runs for once started. The benchmark runs until stopped should it be left
+	// unset.
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// Transactions is the mix of transactions submitted by workers. Workers submit only transfers should it be
+	// left empty.
+	Transactions []WaveletBenchmarkTransaction `json:"transactions,omitempty"`
+
+	// Stop stops a running benchmark before its duration elapses. A stopped benchmark may not be started again.
+	Stop bool `json:"stop,omitempty"`
+
+	// Image is the container image workers are run with. It defaults to the benchmark image of the cluster. Its
+	// `benchmark remote` command must support the -tps and -transactions flags.
+	Image string `json:"image,omitempty"`
+
+	// Schedule is a cron expression in UTC, either of five fields or one of @hourly, @daily, @weekly, @monthly and
+	// @yearly, on which runs of the benchmark are launched. Each run is a WaveletBenchmark of its own owned by this
+	// benchmark, which only keeps track of them. A run is skipped should the previous run still be in progress. The
+	// benchmark runs exactly once should it be left unset.
+	Schedule string `json:"schedule,omitempty"`
+
+	// HistoryLimit is the number of finished runs of a scheduled benchmark kept around alongside their reports. It
+	// defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	HistoryLimit int32 `json:"history_limit,omitempty"`
+
+	// Regression flags runs of a scheduled benchmark that perform worse than a baseline run.
+	Regression *WaveletBenchmarkRegressionSpec `json:"regression,omitempty"`
+}
+
+// WaveletBenchmarkRegressionSpec configures how runs of a scheduled benchmark are compared against a baseline run
+// +k8s:openapi-gen=true
+type WaveletBenchmarkRegressionSpec struct {
+	// ThresholdPercent is how far the TPS of a run may drop below, or its p99 latency may rise above, that of the
+	// baseline as a percentage of the baseline before the run is flagged as a regression. It defaults to 10.
+	// +kubebuilder:validation:Minimum=1
+	ThresholdPercent int32 `json:"threshold_percent,omitempty"`
+
+	// Baseline is the name of the report ConfigMap of a past run that runs are compared against. It defaults to the
+	// first run of the benchmark to complete.
+	Baseline string `json:"baseline,omitempty"`
+}
+
+// WaveletBenchmarkTransactionType is the type of a transaction submitted by benchmark workers.
+// +kubebuilder:validation:Enum=transfer;stake;contract;batch
+type WaveletBenchmarkTransactionType string
+
+const (
+	WaveletBenchmarkTransfer WaveletBenchmarkTransactionType = "transfer"
+	WaveletBenchmarkStake    WaveletBenchmarkTransactionType = "stake"
+	WaveletBenchmarkContract WaveletBenchmarkTransactionType = "contract"
+	WaveletBenchmarkBatch    WaveletBenchmarkTransactionType = "batch"
+)
+
+// WaveletBenchmarkTransaction is the share of transactions of a given type submitted by benchmark workers
+// +k8s:openapi-gen=true
+type WaveletBenchmarkTransaction struct {
+	Type WaveletBenchmarkTransactionType `json:"type"`
+
+	// Weight is the share of transactions of this type relative to the weights of all other types in the mix.
+	// +kubebuilder:validation:Minimum=1
+	Weight int32 `json:"weight"`
+}
+
+// WaveletBenchmarkPhase is a coarse summary of where a benchmark is in its lifecycle.
+type WaveletBenchmarkPhase string
+
+const (
+	// WaveletBenchmarkPending means the benchmark is waiting for its cluster to be ready.
+	WaveletBenchmarkPending WaveletBenchmarkPhase = "Pending"
+
+	// WaveletBenchmarkRunning means workers are submitting transactions to the cluster.
+	WaveletBenchmarkRunning WaveletBenchmarkPhase = "Running"
+
+	// WaveletBenchmarkCompleted means the benchmark ran for its full duration.
+	WaveletBenchmarkCompleted WaveletBenchmarkPhase = "Completed"
+
+	// WaveletBenchmarkStopped means the benchmark was stopped before its duration elapsed.
+	WaveletBenchmarkStopped WaveletBenchmarkPhase = "Stopped"
+
+	// WaveletBenchmarkFailed means the cluster of a running benchmark was deleted, or the schedule of the benchmark
+	// is invalid.
+	WaveletBenchmarkFailed WaveletBenchmarkPhase = "Failed"
+
+	// WaveletBenchmarkScheduled means runs of the benchmark are launched on a schedule.
+	WaveletBenchmarkScheduled WaveletBenchmarkPhase = "Scheduled"
+)
+
+// WaveletBenchmarkStatus defines the observed state of WaveletBenchmark
+// +k8s:openapi-gen=true
+type WaveletBenchmarkStatus struct {
+	ObservedGeneration int64                 `json:"observed_generation,omitempty"`
+	Phase              WaveletBenchmarkPhase `json:"phase,omitempty"`
+	Reason             string                `json:"reason,omitempty"`
+	Message            string                `json:"message,omitempty"`
+
+	Workers      int32 `json:"workers"`
+	ReadyWorkers int32 `json:"ready_workers"`
+
+	// StartTime is when workers were first created against the cluster.
+	StartTime *metav1.Time `json:"start_time,omitempty"`
+
+	// CompletionTime is when the benchmark completed, was stopped or failed.
+	CompletionTime *metav1.Time `json:"completion_time,omitempty"`
+
+	// LastScheduleTime is when the last run of a scheduled benchmark was due.
+	LastScheduleTime *metav1.Time `json:"last_schedule_time,omitempty"`
+
+	// Active is the name of the run of a scheduled benchmark in progress.
+	Active string `json:"active,omitempty"`
+
+	// Runs lists the runs of a scheduled benchmark that are kept around, from oldest to newest.
+	Runs []WaveletBenchmarkRunStatus `json:"runs,omitempty"`
+
+	// Baseline is the run that runs of a scheduled benchmark are compared against to detect regressions. It is
+	// kept in status should the run itself be pruned.
+	Baseline *WaveletBenchmarkRunStatus `json:"baseline,omitempty"`
+
+	// Wallets lists the wallets workers submit transactions with, alongside the nonce of their account on the ledger
+	// of the cluster when the benchmark started. The number of transactions accepted by the cluster is measured from
+	// how far their nonces advanced once the benchmark finishes.
+	Wallets []WaveletBenchmarkWalletStatus `json:"wallets,omitempty"`
+
+	// Results aggregates the results of all workers. It is set once the benchmark finishes. Workers may in addition
+	// log samples of their results to stdout as JSON objects of the form {"event":"benchmark","num_accepted":...,
+	// "num_errors":...,"latencies_ms":[...]}, from which errors and latencies are reported. A benchmark that ran for
+	// its full duration fails should the ledger of its cluster not be reachable and none of its workers have logged a
+	// sample.
+	Results *WaveletBenchmarkResults `json:"results,omitempty"`
+}
+
+// WaveletBenchmarkWalletStatus is a wallet the workers of a benchmark submit transactions with
+// +k8s:openapi-gen=true
+type WaveletBenchmarkWalletStatus struct {
+	PublicKey string `json:"public_key"`
+
+	// Nonce is the nonce of the account of the wallet on the ledger of the cluster when the benchmark started.
+	Nonce uint64 `json:"nonce"`
+}
+
+// WaveletBenchmarkResults aggregates the results reported by the workers of a benchmark
+// +k8s:openapi-gen=true
+type WaveletBenchmarkResults struct {
+	// Workers is the number of workers results were collected from.
+	Workers int32 `json:"workers"`
+
+	// Accepted is the number of transactions submitted by all workers that were accepted by the cluster. It is
+	// measured from how far the nonces of the wallets of workers advanced on the ledger of the cluster, such that
+	// transactions submitted with those wallets by anyone else over the course of the benchmark are counted as well.
+	// It is summed from the samples logged by workers should the ledger not be reachable once the benchmark finishes.
+	Accepted uint64 `json:"accepted"`
+
+	// Errors is the number of transactions submitted by all workers that failed, as logged in their samples.
+	Errors uint64 `json:"errors"`
+
+	// TPS is the number of transactions accepted per second across all workers over the course of the benchmark,
+	// rounded to two decimal places.
+	TPS string `json:"tps"`
+
+	// Latency summarizes the time taken for transactions submitted by all workers to be accepted, as logged in their
+	// samples.
+	Latency WaveletBenchmarkLatency `json:"latency"`
+
+	// Report is the name of the ConfigMap holding the full report of the benchmark, including the results of each
+	// worker.
+	Report string `json:"report,omitempty"`
+}
+
+// WaveletBenchmarkRunStatus describes a single run of a scheduled benchmark
+// +k8s:openapi-gen=true
+type WaveletBenchmarkRunStatus struct {
+	Name           string                   `json:"name"`
+	Phase          WaveletBenchmarkPhase    `json:"phase,omitempty"`
+	StartTime      *metav1.Time             `json:"start_time,omitempty"`
+	CompletionTime *metav1.Time             `json:"completion_time,omitempty"`
+	Results        *WaveletBenchmarkResults `json:"results,omitempty"`
+
+	// Regressed is true should the run perform worse than the baseline beyond the configured threshold.
+	Regressed bool `json:"regressed,omitempty"`
+
+	// Regression describes how the run performed worse than the baseline.
+	Regression string `json:"regression,omitempty"`
+}
+
+// WaveletBenchmarkLatency summarizes a distribution of latencies
+// +k8s:openapi-gen=true
+type WaveletBenchmarkLatency struct {
+	P50 metav1.Duration `json:"p50"`
+	P90 metav1.Duration `json:"p90"`
+	P99 metav1.Duration `json:"p99"`
+	Max metav1.Duration `json:"max"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// WaveletBenchmark is the Schema for the waveletbenchmarks API
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=waveletbenchmarks,singular=waveletbenchmark
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".spec.cluster"
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule",priority=1
+// +kubebuilder:printcolumn:name="Workers",type="integer",JSONPath=".spec.workers"
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.ready_workers"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="TPS",type="string",JSONPath=".status.results.tps"
+// +kubebuilder:printcolumn:name="Started",type="date",JSONPath=".status.start_time"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type WaveletBenchmark struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WaveletBenchmarkSpec   `json:"spec,omitempty"`
+	Status WaveletBenchmarkStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// WaveletBenchmarkList contains a list of WaveletBenchmark
+type WaveletBenchmarkList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []WaveletBenchmark `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&WaveletBenchmark{}, &WaveletBenchmarkList{})
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WaveletSweepSpec defines the desired state of WaveletSweep
+// +k8s:openapi-gen=true
+type WaveletSweepSpec struct {
+	// Cluster is the name of the Wavelet cluster in the same namespace that is reconfigured and benchmarked at each
+	// step of the sweep. The cluster is left configured as per the last step once the sweep completes.
+	// +kubebuilder:validation:MinLength=1
+	Cluster string `json:"cluster"`
+
+	// Size is swept through should it be set, and the size of the cluster is left as is otherwise.
+	Size *WaveletSweepParameter `json:"size,omitempty"`
+
+	// SnowballK is swept through should it be set, and the Snowball K of the cluster is left as is otherwise.
+	SnowballK *WaveletSweepParameter `json:"snowball_k,omitempty"`
+
+	// Benchmark is run against the cluster at each step of the sweep.
+	Benchmark WaveletSweepBenchmarkSpec `json:"benchmark"`
+
+	// StabilizationWindow is how long the cluster is left to settle once it converges to a step before it is
+	// benchmarked.
+	StabilizationWindow *metav1.Duration `json:"stabilization_window,omitempty"`
+
+	// Stop stops the sweep and the benchmark in progress. A stopped sweep may not be started again.
+	Stop bool `json:"stop,omitempty"`
+}
+
+// WaveletSweepParameter lists the values a parameter is swept through, either explicitly or as a range. The
+// steps of a sweep are every combination of the values of all parameters, with Size varying the slowest.
+// +k8s:openapi-gen=true
+type WaveletSweepParameter struct {
+	// Values lists the values of the parameter explicitly.
+	// +kubebuilder:validation:MinItems=1
+	Values []int32 `json:"values,omitempty"`
+
+	// From is the first value of a range. It defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	From int32 `json:"from,omitempty"`
+
+	// To is the last value of a range, which is included should it be a multiple of Step away from From.
+	// +kubebuilder:validation:Minimum=1
+	To int32 `json:"to,omitempty"`
+
+	// Step is the difference between consecutive values of a range. It defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	Step int32 `json:"step,omitempty"`
+}
+
+// WaveletSweepBenchmarkSpec is the template of the benchmark run at each step of a sweep
+// +k8s:openapi-gen=true
+type WaveletSweepBenchmarkSpec struct {
+	// Workers is the number of benchmark clients run against the cluster. It defaults to one per node.
+	// +kubebuilder:validation:Minimum=1
+	Workers int32 `json:"workers,omitempty"`
+
+	Target *WaveletBenchmarkTargetSpec `json:"target,omitempty"`
+
+	// +kubebuilder:validation:Minimum=0
+	TargetTPS uint32 `json:"target_tps,omitempty"`
+
+	Duration     metav1.Duration               `json:"duration"`
+	Transactions []WaveletBenchmarkTransaction `json:"transactions,omitempty"`
+	Image        string                        `json:"image,omitempty"`
+}
+
+// WaveletSweepPhase is a coarse summary of where a sweep is in its lifecycle.
+type WaveletSweepPhase string
+
+const (
+	// WaveletSweepConverging means the cluster is being reconfigured to the current step and has yet to converge.
+	WaveletSweepConverging WaveletSweepPhase = "Converging"
+
+	// WaveletSweepBenchmarking means the cluster converged to the current step and is being benchmarked.
+	WaveletSweepBenchmarking WaveletSweepPhase = "Benchmarking"
+
+	// WaveletSweepCompleted means every step of the sweep was benchmarked.
+	WaveletSweepCompleted WaveletSweepPhase = "Completed"
+
+	// WaveletSweepStopped means the sweep was stopped before every step was benchmarked.
+	WaveletSweepStopped WaveletSweepPhase = "Stopped"
+
+	// WaveletSweepFailed means the cluster being swept was deleted, or rejected being reconfigured to a step.
+	WaveletSweepFailed WaveletSweepPhase = "Failed"
+)
+
+// WaveletSweepResult is a row of the result table of a sweep
+// +k8s:openapi-gen=true
+type WaveletSweepResult struct {
+	Step      int32                    `json:"step"`
+	Size      int32                    `json:"size"`
+	SnowballK int32                    `json:"snowball_k"`
+	Benchmark string                   `json:"benchmark"`
+	Phase     WaveletBenchmarkPhase    `json:"phase"`
+	Results   *WaveletBenchmarkResults `json:"results,omitempty"`
+}
+
+// WaveletSweepStatus defines the observed state of WaveletSweep
+// +k8s:openapi-gen=true
+type WaveletSweepStatus struct {
+	ObservedGeneration int64             `json:"observed_generation,omitempty"`
+	Phase              WaveletSweepPhase `json:"phase,omitempty"`
+	Reason             string            `json:"reason,omitempty"`
+	Message            string            `json:"message,omitempty"`
+
+	// Step is the index of the step in progress, or the number of steps once the sweep completes.
+	Step  int32 `json:"step"`
+	Steps int32 `json:"steps"`
+
+	// ConvergedTime is when the cluster converged to the step in progress.
+	ConvergedTime *metav1.Time `json:"converged_time,omitempty"`
+
+	StartTime      *metav1.Time `json:"start_time,omitempty"`
+	CompletionTime *metav1.Time `json:"completion_time,omitempty"`
+
+	// Results lists the results of every step benchmarked so far.
+	Results []WaveletSweepResult `json:"results,omitempty"`
+
+	// Table is the name of the ConfigMap holding the results of the sweep as CSV.
+	Table string `json:"table,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// WaveletSweep is the Schema for the waveletsweeps API
+// +k8s:openapi-gen=true
+// +kubebuilder:resource:path=waveletsweeps,singular=waveletsweep
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".spec.cluster"
+// +kubebuilder:printcolumn:name="Step",type="integer",JSONPath=".status.step"
+// +kubebuilder:printcolumn:name="Steps",type="integer",JSONPath=".status.steps"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type WaveletSweep struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   WaveletSweepSpec   `json:"spec,omitempty"`
+	Status WaveletSweepStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// WaveletSweepList contains a list of WaveletSweep
+type WaveletSweepList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []WaveletSweep `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&WaveletSweep{}, &WaveletSweepList{})
+}
@@ -24,6 +24,8 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -32,8 +34,8 @@ func (in *Wavelet) DeepCopyInto(out *Wavelet) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -55,6 +57,432 @@ func (in *Wavelet) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletArchiveS3Spec) DeepCopyInto(out *WaveletArchiveS3Spec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletArchiveS3Spec.
+func (in *WaveletArchiveS3Spec) DeepCopy() *WaveletArchiveS3Spec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletArchiveS3Spec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletArchiveSpec) DeepCopyInto(out *WaveletArchiveSpec) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(WaveletArchiveS3Spec)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletArchiveSpec.
+func (in *WaveletArchiveSpec) DeepCopy() *WaveletArchiveSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletArchiveSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletBenchmark) DeepCopyInto(out *WaveletBenchmark) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletBenchmark.
+func (in *WaveletBenchmark) DeepCopy() *WaveletBenchmark {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletBenchmark)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WaveletBenchmark) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletBenchmarkLatency) DeepCopyInto(out *WaveletBenchmarkLatency) {
+	*out = *in
+	out.P50 = in.P50
+	out.P90 = in.P90
+	out.P99 = in.P99
+	out.Max = in.Max
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletBenchmarkLatency.
+func (in *WaveletBenchmarkLatency) DeepCopy() *WaveletBenchmarkLatency {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletBenchmarkLatency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletBenchmarkList) DeepCopyInto(out *WaveletBenchmarkList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WaveletBenchmark, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletBenchmarkList.
+func (in *WaveletBenchmarkList) DeepCopy() *WaveletBenchmarkList {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletBenchmarkList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WaveletBenchmarkList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletBenchmarkRegressionSpec) DeepCopyInto(out *WaveletBenchmarkRegressionSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletBenchmarkRegressionSpec.
+func (in *WaveletBenchmarkRegressionSpec) DeepCopy() *WaveletBenchmarkRegressionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletBenchmarkRegressionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletBenchmarkResults) DeepCopyInto(out *WaveletBenchmarkResults) {
+	*out = *in
+	out.Latency = in.Latency
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletBenchmarkResults.
+func (in *WaveletBenchmarkResults) DeepCopy() *WaveletBenchmarkResults {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletBenchmarkResults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletBenchmarkRunStatus) DeepCopyInto(out *WaveletBenchmarkRunStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = new(WaveletBenchmarkResults)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletBenchmarkRunStatus.
+func (in *WaveletBenchmarkRunStatus) DeepCopy() *WaveletBenchmarkRunStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletBenchmarkRunStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletBenchmarkSpec) DeepCopyInto(out *WaveletBenchmarkSpec) {
+	*out = *in
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(WaveletBenchmarkTargetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Transactions != nil {
+		in, out := &in.Transactions, &out.Transactions
+		*out = make([]WaveletBenchmarkTransaction, len(*in))
+		copy(*out, *in)
+	}
+	if in.Regression != nil {
+		in, out := &in.Regression, &out.Regression
+		*out = new(WaveletBenchmarkRegressionSpec)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletBenchmarkSpec.
+func (in *WaveletBenchmarkSpec) DeepCopy() *WaveletBenchmarkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletBenchmarkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletBenchmarkStatus) DeepCopyInto(out *WaveletBenchmarkStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Runs != nil {
+		in, out := &in.Runs, &out.Runs
+		*out = make([]WaveletBenchmarkRunStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Baseline != nil {
+		in, out := &in.Baseline, &out.Baseline
+		*out = new(WaveletBenchmarkRunStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Wallets != nil {
+		in, out := &in.Wallets, &out.Wallets
+		*out = make([]WaveletBenchmarkWalletStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = new(WaveletBenchmarkResults)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletBenchmarkStatus.
+func (in *WaveletBenchmarkStatus) DeepCopy() *WaveletBenchmarkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletBenchmarkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletBenchmarkTargetSpec) DeepCopyInto(out *WaveletBenchmarkTargetSpec) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletBenchmarkTargetSpec.
+func (in *WaveletBenchmarkTargetSpec) DeepCopy() *WaveletBenchmarkTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletBenchmarkTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletBenchmarkTransaction) DeepCopyInto(out *WaveletBenchmarkTransaction) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletBenchmarkTransaction.
+func (in *WaveletBenchmarkTransaction) DeepCopy() *WaveletBenchmarkTransaction {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletBenchmarkTransaction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletBenchmarkWalletStatus) DeepCopyInto(out *WaveletBenchmarkWalletStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletBenchmarkWalletStatus.
+func (in *WaveletBenchmarkWalletStatus) DeepCopy() *WaveletBenchmarkWalletStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletBenchmarkWalletStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletCondition) DeepCopyInto(out *WaveletCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletCondition.
+func (in *WaveletCondition) DeepCopy() *WaveletCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletConsensusSpec) DeepCopyInto(out *WaveletConsensusSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletConsensusSpec.
+func (in *WaveletConsensusSpec) DeepCopy() *WaveletConsensusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletConsensusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletGenesisAccount) DeepCopyInto(out *WaveletGenesisAccount) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletGenesisAccount.
+func (in *WaveletGenesisAccount) DeepCopy() *WaveletGenesisAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletGenesisAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletGenesisConfigMapSource) DeepCopyInto(out *WaveletGenesisConfigMapSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletGenesisConfigMapSource.
+func (in *WaveletGenesisConfigMapSource) DeepCopy() *WaveletGenesisConfigMapSource {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletGenesisConfigMapSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletGenesisSpec) DeepCopyInto(out *WaveletGenesisSpec) {
+	*out = *in
+	if in.Accounts != nil {
+		in, out := &in.Accounts, &out.Accounts
+		*out = make([]WaveletGenesisAccount, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(WaveletGenesisConfigMapSource)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletGenesisSpec.
+func (in *WaveletGenesisSpec) DeepCopy() *WaveletGenesisSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletGenesisSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletHealthSpec) DeepCopyInto(out *WaveletHealthSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletHealthSpec.
+func (in *WaveletHealthSpec) DeepCopy() *WaveletHealthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletHealthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WaveletList) DeepCopyInto(out *WaveletList) {
 	*out = *in
@@ -62,7 +490,330 @@ func (in *WaveletList) DeepCopyInto(out *WaveletList) {
 	out.ListMeta = in.ListMeta
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Wavelet, len(*in))
+		*out = make([]Wavelet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletList.
+func (in *WaveletList) DeepCopy() *WaveletList {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WaveletList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletNodeHealthStatus) DeepCopyInto(out *WaveletNodeHealthStatus) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletNodeHealthStatus.
+func (in *WaveletNodeHealthStatus) DeepCopy() *WaveletNodeHealthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletNodeHealthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletNodeWalletStatus) DeepCopyInto(out *WaveletNodeWalletStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletNodeWalletStatus.
+func (in *WaveletNodeWalletStatus) DeepCopy() *WaveletNodeWalletStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletNodeWalletStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletProbesSpec) DeepCopyInto(out *WaveletProbesSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletProbesSpec.
+func (in *WaveletProbesSpec) DeepCopy() *WaveletProbesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletProbesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletPuzzleSpec) DeepCopyInto(out *WaveletPuzzleSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletPuzzleSpec.
+func (in *WaveletPuzzleSpec) DeepCopy() *WaveletPuzzleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletPuzzleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletRemediationSpec) DeepCopyInto(out *WaveletRemediationSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletRemediationSpec.
+func (in *WaveletRemediationSpec) DeepCopy() *WaveletRemediationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletRemediationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletRemediationStatus) DeepCopyInto(out *WaveletRemediationStatus) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletRemediationStatus.
+func (in *WaveletRemediationStatus) DeepCopy() *WaveletRemediationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletRemediationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletSpec) DeepCopyInto(out *WaveletSpec) {
+	*out = *in
+	out.Puzzle = in.Puzzle
+	if in.Genesis != nil {
+		in, out := &in.Genesis, &out.Genesis
+		*out = new(WaveletGenesisSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BenchmarkTarget != nil {
+		in, out := &in.BenchmarkTarget, &out.BenchmarkTarget
+		*out = new(WaveletBenchmarkTargetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	out.Consensus = in.Consensus
+	if in.ExtraEnv != nil {
+		in, out := &in.ExtraEnv, &out.ExtraEnv
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.UpdateStrategy = in.UpdateStrategy
+	out.Probes = in.Probes
+	out.Health = in.Health
+	if in.Remediation != nil {
+		in, out := &in.Remediation, &out.Remediation
+		*out = new(WaveletRemediationSpec)
+		**out = **in
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(WaveletStorageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Archive != nil {
+		in, out := &in.Archive, &out.Archive
+		*out = new(WaveletArchiveSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletSpec.
+func (in *WaveletSpec) DeepCopy() *WaveletSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletStatus) DeepCopyInto(out *WaveletStatus) {
+	*out = *in
+	if in.Seeds != nil {
+		in, out := &in.Seeds, &out.Seeds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WalletGeneration != nil {
+		in, out := &in.WalletGeneration, &out.WalletGeneration
+		*out = new(WaveletWalletGenerationStatus)
+		**out = **in
+	}
+	if in.Wallets != nil {
+		in, out := &in.Wallets, &out.Wallets
+		*out = make([]WaveletNodeWalletStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = make([]WaveletNodeHealthStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Remediations != nil {
+		in, out := &in.Remediations, &out.Remediations
+		*out = make([]WaveletRemediationStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]WaveletCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletStatus.
+func (in *WaveletStatus) DeepCopy() *WaveletStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletStorageSpec) DeepCopyInto(out *WaveletStorageSpec) {
+	*out = *in
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	out.Size = in.Size.DeepCopy()
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletStorageSpec.
+func (in *WaveletStorageSpec) DeepCopy() *WaveletStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletSweep) DeepCopyInto(out *WaveletSweep) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletSweep.
+func (in *WaveletSweep) DeepCopy() *WaveletSweep {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletSweep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WaveletSweep) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletSweepBenchmarkSpec) DeepCopyInto(out *WaveletSweepBenchmarkSpec) {
+	*out = *in
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(WaveletBenchmarkTargetSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Duration = in.Duration
+	if in.Transactions != nil {
+		in, out := &in.Transactions, &out.Transactions
+		*out = make([]WaveletBenchmarkTransaction, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletSweepBenchmarkSpec.
+func (in *WaveletSweepBenchmarkSpec) DeepCopy() *WaveletSweepBenchmarkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletSweepBenchmarkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletSweepList) DeepCopyInto(out *WaveletSweepList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WaveletSweep, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -70,18 +821,18 @@ func (in *WaveletList) DeepCopyInto(out *WaveletList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletList.
-func (in *WaveletList) DeepCopy() *WaveletList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletSweepList.
+func (in *WaveletSweepList) DeepCopy() *WaveletSweepList {
 	if in == nil {
 		return nil
 	}
-	out := new(WaveletList)
+	out := new(WaveletSweepList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *WaveletList) DeepCopyObject() runtime.Object {
+func (in *WaveletSweepList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -89,33 +840,142 @@ func (in *WaveletList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WaveletSpec) DeepCopyInto(out *WaveletSpec) {
+func (in *WaveletSweepParameter) DeepCopyInto(out *WaveletSweepParameter) {
 	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletSpec.
-func (in *WaveletSpec) DeepCopy() *WaveletSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletSweepParameter.
+func (in *WaveletSweepParameter) DeepCopy() *WaveletSweepParameter {
 	if in == nil {
 		return nil
 	}
-	out := new(WaveletSpec)
+	out := new(WaveletSweepParameter)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *WaveletStatus) DeepCopyInto(out *WaveletStatus) {
+func (in *WaveletSweepResult) DeepCopyInto(out *WaveletSweepResult) {
 	*out = *in
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = new(WaveletBenchmarkResults)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletStatus.
-func (in *WaveletStatus) DeepCopy() *WaveletStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletSweepResult.
+func (in *WaveletSweepResult) DeepCopy() *WaveletSweepResult {
 	if in == nil {
 		return nil
 	}
-	out := new(WaveletStatus)
+	out := new(WaveletSweepResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletSweepSpec) DeepCopyInto(out *WaveletSweepSpec) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		*out = new(WaveletSweepParameter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SnowballK != nil {
+		in, out := &in.SnowballK, &out.SnowballK
+		*out = new(WaveletSweepParameter)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Benchmark.DeepCopyInto(&out.Benchmark)
+	if in.StabilizationWindow != nil {
+		in, out := &in.StabilizationWindow, &out.StabilizationWindow
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletSweepSpec.
+func (in *WaveletSweepSpec) DeepCopy() *WaveletSweepSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletSweepSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletSweepStatus) DeepCopyInto(out *WaveletSweepStatus) {
+	*out = *in
+	if in.ConvergedTime != nil {
+		in, out := &in.ConvergedTime, &out.ConvergedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]WaveletSweepResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletSweepStatus.
+func (in *WaveletSweepStatus) DeepCopy() *WaveletSweepStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletSweepStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletUpdateStrategy) DeepCopyInto(out *WaveletUpdateStrategy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletUpdateStrategy.
+func (in *WaveletUpdateStrategy) DeepCopy() *WaveletUpdateStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletUpdateStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaveletWalletGenerationStatus) DeepCopyInto(out *WaveletWalletGenerationStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaveletWalletGenerationStatus.
+func (in *WaveletWalletGenerationStatus) DeepCopy() *WaveletWalletGenerationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WaveletWalletGenerationStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -32,9 +32,42 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.Wavelet":       schema_pkg_apis_wavelet_v1alpha1_Wavelet(ref),
-		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSpec":   schema_pkg_apis_wavelet_v1alpha1_WaveletSpec(ref),
-		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletStatus": schema_pkg_apis_wavelet_v1alpha1_WaveletStatus(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.Wavelet":                        schema_pkg_apis_wavelet_v1alpha1_Wavelet(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletArchiveS3Spec":           schema_pkg_apis_wavelet_v1alpha1_WaveletArchiveS3Spec(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletArchiveSpec":             schema_pkg_apis_wavelet_v1alpha1_WaveletArchiveSpec(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmark":               schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmark(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkLatency":        schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkLatency(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkRegressionSpec": schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkRegressionSpec(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkResults":        schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkResults(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkRunStatus":      schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkRunStatus(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkSpec":           schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkSpec(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkStatus":         schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkStatus(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkTargetSpec":     schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkTargetSpec(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkTransaction":    schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkTransaction(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkWalletStatus":   schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkWalletStatus(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletCondition":               schema_pkg_apis_wavelet_v1alpha1_WaveletCondition(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletConsensusSpec":           schema_pkg_apis_wavelet_v1alpha1_WaveletConsensusSpec(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletGenesisAccount":          schema_pkg_apis_wavelet_v1alpha1_WaveletGenesisAccount(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletGenesisConfigMapSource":  schema_pkg_apis_wavelet_v1alpha1_WaveletGenesisConfigMapSource(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletGenesisSpec":             schema_pkg_apis_wavelet_v1alpha1_WaveletGenesisSpec(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletHealthSpec":              schema_pkg_apis_wavelet_v1alpha1_WaveletHealthSpec(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletNodeHealthStatus":        schema_pkg_apis_wavelet_v1alpha1_WaveletNodeHealthStatus(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletNodeWalletStatus":        schema_pkg_apis_wavelet_v1alpha1_WaveletNodeWalletStatus(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletProbesSpec":              schema_pkg_apis_wavelet_v1alpha1_WaveletProbesSpec(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletPuzzleSpec":              schema_pkg_apis_wavelet_v1alpha1_WaveletPuzzleSpec(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletRemediationSpec":         schema_pkg_apis_wavelet_v1alpha1_WaveletRemediationSpec(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletRemediationStatus":       schema_pkg_apis_wavelet_v1alpha1_WaveletRemediationStatus(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSpec":                    schema_pkg_apis_wavelet_v1alpha1_WaveletSpec(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletStatus":                  schema_pkg_apis_wavelet_v1alpha1_WaveletStatus(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletStorageSpec":             schema_pkg_apis_wavelet_v1alpha1_WaveletStorageSpec(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweep":                   schema_pkg_apis_wavelet_v1alpha1_WaveletSweep(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepBenchmarkSpec":      schema_pkg_apis_wavelet_v1alpha1_WaveletSweepBenchmarkSpec(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepParameter":          schema_pkg_apis_wavelet_v1alpha1_WaveletSweepParameter(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepResult":             schema_pkg_apis_wavelet_v1alpha1_WaveletSweepResult(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepSpec":               schema_pkg_apis_wavelet_v1alpha1_WaveletSweepSpec(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepStatus":             schema_pkg_apis_wavelet_v1alpha1_WaveletSweepStatus(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletUpdateStrategy":          schema_pkg_apis_wavelet_v1alpha1_WaveletUpdateStrategy(ref),
+		"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletWalletGenerationStatus":  schema_pkg_apis_wavelet_v1alpha1_WaveletWalletGenerationStatus(ref),
 	}
 }
 
@@ -82,23 +115,1830 @@ func schema_pkg_apis_wavelet_v1alpha1_Wavelet(ref common.ReferenceCallback) comm
 	}
 }
 
+func schema_pkg_apis_wavelet_v1alpha1_WaveletArchiveS3Spec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletArchiveS3Spec defines an S3-compatible object store archives are uploaded to",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"endpoint": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Endpoint is the URL of the object store, i.e. http://minio.default.svc:9000.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"bucket": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"prefix": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Prefix is prepended to the key of every archived object.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"credentials_secret": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CredentialsSecret is the name of a secret in the namespace of the cluster holding the access_key_id and secret_access_key used to authenticate against the object store.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"endpoint", "bucket", "credentials_secret"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletArchiveSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletArchiveSpec defines the destination a cluster is archived to before it is torn down. Only the ledgers of nodes with persistent storage are archived; the genesis and wallets of a cluster are always archived.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"persistent_volume_claim": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PersistentVolumeClaim is the name of an existing volume claim in the namespace of the cluster that archives are copied into.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"s3": {
+						SchemaProps: spec.SchemaProps{
+							Description: "S3 configures an S3-compatible object store, such as MinIO, that archives are uploaded to.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletArchiveS3Spec"),
+						},
+					},
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Image is the container image archive jobs are run with. It defaults to busybox when archiving to a volume claim, and to the MinIO client when archiving to an object store.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletArchiveS3Spec"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmark(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletBenchmark is the Schema for the waveletbenchmarks API",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkSpec", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkLatency(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletBenchmarkLatency summarizes a distribution of latencies",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"p50": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"p90": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"p99": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"max": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+				},
+				Required: []string{"p50", "p90", "p99", "max"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkRegressionSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletBenchmarkRegressionSpec configures how runs of a scheduled benchmark are compared against a baseline run",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"threshold_percent": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ThresholdPercent is how far the TPS of a run may drop below, or its p99 latency may rise above, that of the baseline as a percentage of the baseline before the run is flagged as a regression. It defaults to 10.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"baseline": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Baseline is the name of the report ConfigMap of a past run that runs are compared against. It defaults to the first run of the benchmark to complete.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkResults(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletBenchmarkResults aggregates the results reported by the workers of a benchmark",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"workers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Workers is the number of workers results were collected from.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"accepted": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Accepted is the number of transactions submitted by all workers that were accepted by the cluster. It is measured from how far the nonces of the wallets of workers advanced on the ledger of the cluster, such that transactions submitted with those wallets by anyone else over the course of the benchmark are counted as well. It is summed from the samples logged by workers should the ledger not be reachable once the benchmark finishes.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"errors": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Errors is the number of transactions submitted by all workers that failed, as logged in their samples.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"tps": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TPS is the number of transactions accepted per second across all workers over the course of the benchmark, rounded to two decimal places.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"latency": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Latency summarizes the time taken for transactions submitted by all workers to be accepted, as logged in their samples.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkLatency"),
+						},
+					},
+					"report": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Report is the name of the ConfigMap holding the full report of the benchmark, including the results of each worker.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"workers", "accepted", "errors", "tps", "latency"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkLatency"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkRunStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletBenchmarkRunStatus describes a single run of a scheduled benchmark",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"start_time": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"completion_time": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"results": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkResults"),
+						},
+					},
+					"regressed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Regressed is true should the run perform worse than the baseline beyond the configured threshold.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"regression": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Regression describes how the run performed worse than the baseline.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkResults", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletBenchmarkSpec defines the desired state of WaveletBenchmark",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"cluster": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Cluster is the name of the Wavelet cluster in the same namespace the benchmark is run against.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"workers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Workers is the number of benchmark clients run against the cluster. There may be more workers than there are nodes in the cluster.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"target": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Target configures which nodes workers target, and which wallets they use. Workers are spread round-robin across all nodes, each using the wallet of the node it targets, should it be left unset.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkTargetSpec"),
+						},
+					},
+					"target_tps": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TargetTPS is the number of transactions per second the benchmark aims to submit across all workers, and is split evenly between them. Workers submit transactions as fast as they can should it be 0.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"duration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Duration is how long the benchmark runs for once started. The benchmark runs until stopped should it be left unset.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"transactions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Transactions is the mix of transactions submitted by workers. Workers submit only transfers should it be left empty.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkTransaction"),
+									},
+								},
+							},
+						},
+					},
+					"stop": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Stop stops a running benchmark before its duration elapses. A stopped benchmark may not be started again.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Image is the container image workers are run with. It defaults to the benchmark image of the cluster. Its `benchmark remote` command must support the -tps and -transactions flags.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"schedule": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Schedule is a cron expression in UTC, either of five fields or one of @hourly, @daily, @weekly, @monthly and @yearly, on which runs of the benchmark are launched. Each run is a WaveletBenchmark of its own owned by this benchmark, which only keeps track of them. A run is skipped should the previous run still be in progress. The benchmark runs exactly once should it be left unset.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"history_limit": {
+						SchemaProps: spec.SchemaProps{
+							Description: "HistoryLimit is the number of finished runs of a scheduled benchmark kept around alongside their reports. It defaults to 10.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"regression": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Regression flags runs of a scheduled benchmark that perform worse than a baseline run.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkRegressionSpec"),
+						},
+					},
+				},
+				Required: []string{"cluster", "workers"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkRegressionSpec", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkTargetSpec", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkTransaction", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletBenchmarkStatus defines the observed state of WaveletBenchmark",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"observed_generation": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"workers": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"ready_workers": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"start_time": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StartTime is when workers were first created against the cluster.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"completion_time": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CompletionTime is when the benchmark completed, was stopped or failed.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"last_schedule_time": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastScheduleTime is when the last run of a scheduled benchmark was due.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"active": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Active is the name of the run of a scheduled benchmark in progress.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"runs": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Runs lists the runs of a scheduled benchmark that are kept around, from oldest to newest.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkRunStatus"),
+									},
+								},
+							},
+						},
+					},
+					"baseline": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Baseline is the run that runs of a scheduled benchmark are compared against to detect regressions. It is kept in status should the run itself be pruned.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkRunStatus"),
+						},
+					},
+					"wallets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Wallets lists the wallets workers submit transactions with, alongside the nonce of their account on the ledger of the cluster when the benchmark started. The number of transactions accepted by the cluster is measured from how far their nonces advanced once the benchmark finishes.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkWalletStatus"),
+									},
+								},
+							},
+						},
+					},
+					"results": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Results aggregates the results of all workers. It is set once the benchmark finishes. Workers may in addition log samples of their results to stdout as JSON objects of the form {\"event\":\"benchmark\",\"num_accepted\":..., \"num_errors\":...,\"latencies_ms\":[...]}, from which errors and latencies are reported. A benchmark that ran for its full duration fails should the ledger of its cluster not be reachable and none of its workers have logged a sample.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkResults"),
+						},
+					},
+				},
+				Required: []string{"workers", "ready_workers"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkResults", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkRunStatus", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkWalletStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkTargetSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletBenchmarkTargetSpec configures which nodes benchmark clients submit transactions to, and which wallets they sign them with",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"strategy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Strategy defaults to RoundRobin.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"selector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Selector selects the node pods targeted by the Selector strategy.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"),
+						},
+					},
+					"wallets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Wallets defaults to Shared.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.LabelSelector"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkTransaction(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletBenchmarkTransaction is the share of transactions of a given type submitted by benchmark workers",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"weight": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Weight is the share of transactions of this type relative to the weights of all other types in the mix.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+				Required: []string{"type", "weight"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletBenchmarkWalletStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletBenchmarkWalletStatus is a wallet the workers of a benchmark submit transactions with",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"public_key": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"nonce": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Nonce is the nonce of the account of the wallet on the ledger of the cluster when the benchmark started.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+				},
+				Required: []string{"public_key", "nonce"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletCondition(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletCondition describes the state of a Wavelet cluster at a certain point.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"observed_generation": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"last_transition_time": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+				Required: []string{"type", "status"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletConsensusSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletConsensusSpec defines the Snowball consensus parameters nodes are run with",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"snowball_k": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SnowballK is the number of peers sampled per query. It defaults to 10.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"snowball_alpha": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SnowballAlpha is the fraction of sampled peers that must agree on a preference for a query to succeed. It defaults to the default of the nodes.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"snowball_beta": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SnowballBeta is the number of consecutive successful queries required to finalize a round. It defaults to 20.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletGenesisAccount(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletGenesisAccount defines an account allocated at genesis",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"public_key": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PublicKey is the hex-encoded public key of the account. A wallet is generated for the account and stored in the wallet secret of the cluster should it be left unset.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"balance": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"stake": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"reward": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletGenesisConfigMapSource(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletGenesisConfigMapSource selects a key of a ConfigMap holding a genesis JSON file",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the name of a ConfigMap in the namespace of the cluster.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"key": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Key defaults to genesis.json.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"name"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletGenesisSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletGenesisSpec defines additional state allocated at genesis",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"accounts": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Accounts are allocated at genesis on top of the rich wallets of the cluster.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletGenesisAccount"),
+									},
+								},
+							},
+						},
+					},
+					"config_map": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConfigMap points at a complete genesis JSON file that rich wallets and accounts are allocated on top of. It may describe any state nodes understand, such as contracts.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletGenesisConfigMapSource"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletGenesisAccount", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletGenesisConfigMapSource"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletHealthSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletHealthSpec configures when nodes are considered stuck, and when the cluster as a whole is considered partitioned or stalled, based on the ledger state the operator queries from each node",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"max_round_lag": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxRoundLag is the number of rounds a node may trail the median round of the cluster before it is considered to be lagging. It defaults to 10.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"min_peers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MinPeers is the number of peers a node must be connected to in order to be healthy. Nodes of a cluster of a single node are exempt. It defaults to 1.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"stuck_seconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StuckSeconds is the time a node may lag, be out of sync, be isolated from its peers or have its API be unreachable before it is considered stuck. It is also the time the cluster may be partitioned before it is reported as such. It defaults to 120.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"stall_seconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StallSeconds is the time the median round of the cluster may not advance while benchmarks run against it before the cluster is considered stalled. It defaults to 300.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletNodeHealthStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletNodeHealthStatus describes the ledger state of a node as last queried by the operator",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"node": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"state": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"uid": {
+						SchemaProps: spec.SchemaProps{
+							Description: "UID is the UID of the node pod the state was queried from, such that the state of a node is not mistaken for that of the pod replacing it.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"stuck": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Stuck is set once the node has not been healthy for longer than the stuck threshold of the cluster.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"round": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"peers": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"last_transaction": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastTransaction is the ID of the last transaction the node accepted.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"since": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Since is the time the node entered its current state.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+				Required: []string{"node", "state", "round", "peers", "since"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletNodeWalletStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletNodeWalletStatus describes the wallet assigned to a node",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"node": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"wallet": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Wallet is the path to the wallet within the node container. It is empty until the wallet is generated.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"public_key": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PublicKey is the hex-encoded public key of the wallet. It is unknown for the wallet built into the node image.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"funded": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Funded reports whether the wallet was allocated a balance at genesis.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"node", "funded"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletProbesSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletProbesSpec configures how the kubelet probes the HTTP API of each node. A node is live and ready for as long as its API responds. The API of wavelet has no endpoint that fails while a node is out of sync or lacks peers, so those are judged by the health checks of the operator instead, as configured by WaveletHealthSpec.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"initial_delay_seconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "InitialDelaySeconds is the time a node is given to start before its liveness is probed. It defaults to 30.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"period_seconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PeriodSeconds is the interval at which nodes are probed. It defaults to 10.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"failure_threshold": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FailureThreshold is the number of consecutive failed liveness probes after which a node is restarted. It defaults to 6.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletPuzzleSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletPuzzleSpec defines the difficulty of the S/Kademlia static and dynamic puzzles",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"c1": {
+						SchemaProps: spec.SchemaProps{
+							Description: "C1 is the number of leading zero bits the checksum of a public key must have. It defaults to 16.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"c2": {
+						SchemaProps: spec.SchemaProps{
+							Description: "C2 is the number of leading zero bits the checksum of a public key xor'ed with a nonce must have. It defaults to 16.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletRemediationSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletRemediationSpec configures how often stuck nodes may be recreated",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"max_per_hour": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxPerHour is the maximum number of nodes recreated within any hour across the cluster. It defaults to 3.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"backoff_seconds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BackoffSeconds is the time a node must wait after being recreated before it may be recreated again. It doubles with every time the node was recreated within the last hour, up to an hour. It defaults to 60.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"wipe_ledger": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WipeLedger deletes the ledger volume of a node alongside it, such that the node syncs its ledger from scratch. It has no effect unless the cluster is configured with persistent storage.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletRemediationStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletRemediationStatus describes a node recreated by the operator",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"node": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"time": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"state": {
+						SchemaProps: spec.SchemaProps{
+							Description: "State is the state the node was stuck in.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"wiped_ledger": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WipedLedger reports whether the ledger volume of the node was deleted alongside it.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+				Required: []string{"node", "time", "state"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
 func schema_pkg_apis_wavelet_v1alpha1_WaveletSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "WaveletSpec defines the desired state of Wavelet",
+				Description: "WaveletSpec defines the desired state of Wavelet",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"size": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Size is the number of nodes in the cluster. All nodes are torn down should it be 0.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"num_seeds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NumSeeds is the number of nodes, starting from the bootstrap node, every node bootstraps to through their stable DNS names. Nodes keep being able to join the cluster for as long as any seed is up. It defaults to 1, and may not exceed Size unless Size is 0.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"num_rich_wallets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NumRichWallets is the number of wallets funded at genesis. The node with ordinal i is assigned the i-th rich wallet, and the bootstrap node the wallet built into its image.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"node_balance": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NodeBalance is allocated at genesis to the wallet of every node that is not assigned a rich wallet. Nodes added after the cluster is created are never funded. It may not be changed once the cluster is created.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"wallet_seed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WalletSeed deterministically derives the keys of all wallets generated for the cluster, such that clusters sharing the same seed and genesis parameters share a byte-identical genesis. Wallets are generated from fresh randomness should it be left unset. It may not be changed once the cluster is created.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"puzzle": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Puzzle configures the difficulty of the S/Kademlia puzzles that the keys of all nodes and wallets must solve. It may not be changed once the cluster is created.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletPuzzleSpec"),
+						},
+					},
+					"genesis": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Genesis configures additional state allocated at genesis. It may not be changed once the cluster is created.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletGenesisSpec"),
+						},
+					},
+					"num_benchmark_pods": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NumBenchmarkPods is the number of benchmark pods run against the cluster for as long as it exists. Benchmarks with parameters and a lifecycle of their own are run through WaveletBenchmark instead.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"benchmark_target": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BenchmarkTarget configures which nodes benchmark pods target, and which wallets they use. Benchmark pods are spread round-robin across all nodes, each using the wallet of the node it targets, should it be left unset.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkTargetSpec"),
+						},
+					},
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Image is the container image nodes are run with. It defaults to the latest build of Wavelet.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"benchmark_image": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BenchmarkImage is the container image benchmark pods are run with. It defaults to Image.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"image_pull_policy": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"image_pull_secrets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ImagePullSecrets defaults to a single secret named regcred.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.LocalObjectReference"),
+									},
+								},
+							},
+						},
+					},
+					"consensus": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletConsensusSpec"),
+						},
+					},
+					"memory_max": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MemoryMax is the maximum amount of memory in MiB a node may use. It defaults to 4096.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"extra_env": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExtraEnv is appended to the environment of every node, and takes precedence over variables set by the operator.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("k8s.io/api/core/v1.EnvVar"),
+									},
+								},
+							},
+						},
+					},
+					"extra_args": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExtraArgs are passed as flags to every node.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"update_strategy": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletUpdateStrategy"),
+						},
+					},
+					"probes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Probes configures the readiness and liveness probes nodes are run with.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletProbesSpec"),
+						},
+					},
+					"health": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Health configures how the operator judges the ledger state of nodes it periodically queries.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletHealthSpec"),
+						},
+					},
+					"remediation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Remediation has the operator recreate nodes that are stuck lagging behind, forked from or isolated from the rest of the cluster. Stuck nodes are left alone should it be unset.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletRemediationSpec"),
+						},
+					},
+					"storage": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Storage configures a persistent volume for the ledger database of each node. Nodes keep their ledger on the ephemeral filesystem of their container should it be left unset.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletStorageSpec"),
+						},
+					},
+					"archive": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Archive configures where the ledgers of nodes and the genesis and wallets of the cluster are archived to before the cluster is torn down. Nothing is archived should it be left unset.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletArchiveSpec"),
+						},
+					},
+				},
+				Required: []string{"size", "num_rich_wallets", "num_benchmark_pods"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletArchiveSpec", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkTargetSpec", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletConsensusSpec", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletGenesisSpec", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletHealthSpec", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletProbesSpec", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletPuzzleSpec", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletRemediationSpec", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletStorageSpec", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletUpdateStrategy", "k8s.io/api/core/v1.EnvVar", "k8s.io/api/core/v1.LocalObjectReference"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletStatus defines the observed state of Wavelet",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"observed_generation": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"nodes": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"ready_nodes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ReadyNodes is the number of nodes that are ready and were last judged healthy by the operator (see Health).",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"updated_nodes": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"benchmark_pods": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"bootstrap_ip": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"seeds": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Seeds lists the addresses every node bootstraps to.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"string"},
+										Format: "",
+									},
+								},
+							},
+						},
+					},
+					"wallet_generation": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WalletGeneration reports the progress of generating the wallets of the cluster. It is only set while wallets are being generated.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletWalletGenerationStatus"),
+						},
+					},
+					"wallets": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Wallets lists the wallet each node of the cluster is assigned, in order of their ordinal.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletNodeWalletStatus"),
+									},
+								},
+							},
+						},
+					},
+					"round": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Round is the median round of the running nodes of the cluster, as last queried by the operator.",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"health": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Health lists the ledger state of each running node of the cluster as last queried by the operator, in order of their ordinal.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletNodeHealthStatus"),
+									},
+								},
+							},
+						},
+					},
+					"remediations": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Remediations lists the nodes recreated by the operator within the last hour, in the order they were recreated.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletRemediationStatus"),
+									},
+								},
+							},
+						},
+					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletCondition"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"nodes", "ready_nodes", "updated_nodes", "benchmark_pods"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletCondition", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletNodeHealthStatus", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletNodeWalletStatus", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletRemediationStatus", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletWalletGenerationStatus"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletStorageSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletStorageSpec defines the persistent volume provisioned for the ledger database of each node",
 				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"storage_class": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"size": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/api/resource.Quantity"),
+						},
+					},
+					"retention_policy": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RetentionPolicy defaults to Retain.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"size"},
 			},
 		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/api/resource.Quantity"},
 	}
 }
 
-func schema_pkg_apis_wavelet_v1alpha1_WaveletStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_wavelet_v1alpha1_WaveletSweep(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "WaveletStatus defines the observed state of Wavelet",
+				Description: "WaveletSweep is the Schema for the waveletsweeps API",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepSpec"),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepStatus"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepSpec", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepStatus", "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletSweepBenchmarkSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletSweepBenchmarkSpec is the template of the benchmark run at each step of a sweep",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"workers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Workers is the number of benchmark clients run against the cluster. It defaults to one per node.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"target": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkTargetSpec"),
+						},
+					},
+					"target_tps": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"duration": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"transactions": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkTransaction"),
+									},
+								},
+							},
+						},
+					},
+					"image": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+				Required: []string{"duration"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkTargetSpec", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkTransaction", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletSweepParameter(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletSweepParameter lists the values a parameter is swept through, either explicitly or as a range. The steps of a sweep are every combination of the values of all parameters, with Size varying the slowest.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"values": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Values lists the values of the parameter explicitly.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type:   []string{"integer"},
+										Format: "int32",
+									},
+								},
+							},
+						},
+					},
+					"from": {
+						SchemaProps: spec.SchemaProps{
+							Description: "From is the first value of a range. It defaults to 1.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"to": {
+						SchemaProps: spec.SchemaProps{
+							Description: "To is the last value of a range, which is included should it be a multiple of Step away from From.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"step": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Step is the difference between consecutive values of a range. It defaults to 1.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletSweepResult(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletSweepResult is a row of the result table of a sweep",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"step": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"size": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"snowball_k": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"benchmark": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"results": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkResults"),
+						},
+					},
+				},
+				Required: []string{"step", "size", "snowball_k", "benchmark", "phase"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletBenchmarkResults"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletSweepSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletSweepSpec defines the desired state of WaveletSweep",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"cluster": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Cluster is the name of the Wavelet cluster in the same namespace that is reconfigured and benchmarked at each step of the sweep. The cluster is left configured as per the last step once the sweep completes.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"size": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Size is swept through should it be set, and the size of the cluster is left as is otherwise.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepParameter"),
+						},
+					},
+					"snowball_k": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SnowballK is swept through should it be set, and the Snowball K of the cluster is left as is otherwise.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepParameter"),
+						},
+					},
+					"benchmark": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Benchmark is run against the cluster at each step of the sweep.",
+							Ref:         ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepBenchmarkSpec"),
+						},
+					},
+					"stabilization_window": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StabilizationWindow is how long the cluster is left to settle once it converges to a step before it is benchmarked.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Duration"),
+						},
+					},
+					"stop": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Stop stops the sweep and the benchmark in progress. A stopped sweep may not be started again.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"cluster", "benchmark"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepBenchmarkSpec", "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepParameter", "k8s.io/apimachinery/pkg/apis/meta/v1.Duration"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletSweepStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletSweepStatus defines the observed state of WaveletSweep",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"observed_generation": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int64",
+						},
+					},
+					"phase": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"step": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Step is the index of the step in progress, or the number of steps once the sweep completes.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"steps": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"converged_time": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ConvergedTime is when the cluster converged to the step in progress.",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"start_time": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"completion_time": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"results": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Results lists the results of every step benchmarked so far.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepResult"),
+									},
+								},
+							},
+						},
+					},
+					"table": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Table is the name of the ConfigMap holding the results of the sweep as CSV.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"step", "steps"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1.WaveletSweepResult", "k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletUpdateStrategy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletUpdateStrategy configures how nodes are replaced once the spec they would be rendered with changes",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"max_unavailable": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MaxUnavailable is the maximum number of nodes that may be unavailable while nodes are being replaced. It defaults to 1.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"bootstrap_order": {
+						SchemaProps: spec.SchemaProps{
+							Description: "BootstrapOrder defaults to Last.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_wavelet_v1alpha1_WaveletWalletGenerationStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "WaveletWalletGenerationStatus reports how many of the wallets of a cluster have been generated",
 				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"generated": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"total": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+				},
+				Required: []string{"generated", "total"},
 			},
 		},
 	}
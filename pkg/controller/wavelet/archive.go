@@ -0,0 +1,249 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"fmt"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"path"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ImageArchive   = "busybox"
+	ImageArchiveS3 = "minio/mc"
+)
+
+// ArchiveMountPath is the directory in which the destination volume claim of an archive is mounted in archive jobs.
+const ArchiveMountPath = "/archive"
+
+// archiveStagingPath is the directory archives are staged in before being uploaded to an object store.
+const archiveStagingPath = "/staging"
+
+// waveletArchiveScript packs the directory $ARCHIVE_SOURCE into the gzipped tarball $ARCHIVE_FILE. Symlinks are
+// followed, and the hidden directories Kubernetes uses to atomically update secret volumes are skipped.
+const waveletArchiveScript = `set -e
+mkdir -p "$(dirname "$ARCHIVE_FILE")"
+tar -czhf "$ARCHIVE_FILE" -C "$ARCHIVE_SOURCE" --exclude './..*' .`
+
+// waveletArchiveTarget is something archived before a cluster is torn down, being either the wallet secret of the
+// cluster or the ledger volume of one of its nodes.
+type waveletArchiveTarget struct {
+	name   string
+	volume corev1.Volume
+	mount  corev1.VolumeMount
+}
+
+func getWaveletArchiveWalletTarget(cluster *waveletv1alpha1.Wavelet) waveletArchiveTarget {
+	volume, mount := getWaveletWalletVolume(GetWaveletWalletSecretName(cluster))
+
+	return waveletArchiveTarget{name: "wallets", volume: volume, mount: mount}
+}
+
+func getWaveletArchiveLedgerTarget(claimName string, idx uint) waveletArchiveTarget {
+	return waveletArchiveTarget{
+		name: fmt.Sprintf("node-%d", idx),
+		volume: corev1.Volume{
+			Name: "ledger",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: claimName,
+					ReadOnly:  true,
+				},
+			},
+		},
+		mount: corev1.VolumeMount{
+			Name:      "ledger",
+			MountPath: LedgerMountPath,
+			ReadOnly:  true,
+		},
+	}
+}
+
+// getWaveletArchiveKey returns the path, relative to the root of an archive destination, that a target of a cluster
+// is archived to. Archives are keyed by the time the cluster was deleted, such that a cluster recreated under the
+// same name never overwrites the archives of its predecessor.
+func getWaveletArchiveKey(cluster *waveletv1alpha1.Wavelet, name string) string {
+	key := path.Join(getWaveletArchiveDir(cluster), name+".tar.gz")
+
+	if s3 := cluster.Spec.Archive.S3; s3 != nil {
+		key = path.Join(s3.Prefix, key)
+	}
+
+	return key
+}
+
+func getWaveletArchiveDir(cluster *waveletv1alpha1.Wavelet) string {
+	timestamp := metav1.Now()
+
+	if deleted := cluster.GetDeletionTimestamp(); deleted != nil {
+		timestamp = *deleted
+	}
+
+	return path.Join(cluster.Namespace, cluster.Name, timestamp.UTC().Format("20060102T150405Z"))
+}
+
+// getWaveletArchiveLocation describes where all archives of a cluster may be found.
+func getWaveletArchiveLocation(cluster *waveletv1alpha1.Wavelet) string {
+	archive := cluster.Spec.Archive
+
+	if archive.S3 != nil {
+		return fmt.Sprintf("%s/%s", strings.TrimSuffix(archive.S3.Endpoint, "/"), path.Join(archive.S3.Bucket, archive.S3.Prefix, getWaveletArchiveDir(cluster)))
+	}
+
+	return fmt.Sprintf("pvc/%s/%s", archive.PersistentVolumeClaim, getWaveletArchiveDir(cluster))
+}
+
+func getWaveletArchiveJobName(cluster *waveletv1alpha1.Wavelet, target waveletArchiveTarget) string {
+	return fmt.Sprintf("%s-archive-%s", cluster.Name, target.name)
+}
+
+func getWaveletArchiveJob(cluster *waveletv1alpha1.Wavelet, target waveletArchiveTarget) *batchv1.Job {
+	backoffLimit := int32(3)
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getWaveletArchiveJobName(cluster, target),
+			Namespace: cluster.Namespace,
+			Labels:    LabelsForWavelet(cluster.Name, "archive"),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: LabelsForWavelet(cluster.Name, "archive"),
+				},
+				Spec: getWaveletArchivePodSpec(cluster, target),
+			},
+		},
+	}
+}
+
+// getWaveletArchivePodSpec renders a pod that archives a target straight into the destination volume claim of a
+// cluster, or that stages the archive in an empty directory before uploading it to the object store of a cluster.
+func getWaveletArchivePodSpec(cluster *waveletv1alpha1.Wavelet, target waveletArchiveTarget) corev1.PodSpec {
+	archive := cluster.Spec.Archive
+	key := getWaveletArchiveKey(cluster, target.name)
+
+	pack := corev1.Container{
+		Name:    "archive",
+		Image:   ImageArchive,
+		Command: []string{"/bin/sh", "-c", waveletArchiveScript},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "ARCHIVE_SOURCE",
+				Value: target.mount.MountPath,
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{target.mount},
+	}
+
+	spec := corev1.PodSpec{
+		RestartPolicy:    corev1.RestartPolicyNever,
+		Volumes:          []corev1.Volume{target.volume},
+		ImagePullSecrets: getWaveletImagePullSecrets(cluster),
+	}
+
+	if archive.S3 == nil {
+		if len(archive.Image) > 0 {
+			pack.Image = archive.Image
+		}
+
+		pack.Env = append(pack.Env, corev1.EnvVar{Name: "ARCHIVE_FILE", Value: path.Join(ArchiveMountPath, key)})
+		pack.VolumeMounts = append(pack.VolumeMounts, corev1.VolumeMount{Name: "archive", MountPath: ArchiveMountPath})
+
+		spec.Volumes = append(spec.Volumes, corev1.Volume{
+			Name: "archive",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: archive.PersistentVolumeClaim,
+				},
+			},
+		})
+
+		spec.Containers = []corev1.Container{pack}
+
+		return spec
+	}
+
+	staged := path.Join(archiveStagingPath, target.name+".tar.gz")
+
+	pack.Env = append(pack.Env, corev1.EnvVar{Name: "ARCHIVE_FILE", Value: staged})
+	pack.VolumeMounts = append(pack.VolumeMounts, corev1.VolumeMount{Name: "staging", MountPath: archiveStagingPath})
+
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: "staging",
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+
+	image := ImageArchiveS3
+
+	if len(archive.Image) > 0 {
+		image = archive.Image
+	}
+
+	scheme, host := "https", archive.S3.Endpoint
+
+	if parts := strings.SplitN(archive.S3.Endpoint, "://", 2); len(parts) == 2 {
+		scheme, host = parts[0], strings.TrimSuffix(parts[1], "/")
+	}
+
+	credential := func(key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: archive.S3.CredentialsSecret},
+				Key:                  key,
+			},
+		}
+	}
+
+	spec.InitContainers = []corev1.Container{pack}
+	spec.Containers = []corev1.Container{
+		{
+			Name:    "upload",
+			Image:   image,
+			Command: []string{"mc", "cp", staged, path.Join("archive", archive.S3.Bucket, key)},
+			Env: []corev1.EnvVar{
+				{
+					Name:      "ARCHIVE_ACCESS_KEY_ID",
+					ValueFrom: credential("access_key_id"),
+				},
+				{
+					Name:      "ARCHIVE_SECRET_ACCESS_KEY",
+					ValueFrom: credential("secret_access_key"),
+				},
+				{
+					// The MinIO client reads the endpoint and credentials of the alias "archive" from this variable.
+					Name:  "MC_HOST_archive",
+					Value: fmt.Sprintf("%s://$(ARCHIVE_ACCESS_KEY_ID):$(ARCHIVE_SECRET_ACCESS_KEY)@%s", scheme, host),
+				},
+			},
+			VolumeMounts: []corev1.VolumeMount{{Name: "staging", MountPath: archiveStagingPath, ReadOnly: true}},
+		},
+	}
+
+	return spec
+}
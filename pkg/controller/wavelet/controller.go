@@ -21,19 +21,24 @@ package wavelet
 
 import (
 	"context"
+	"fmt"
+	"github.com/go-logr/logr"
 	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
 	"k8s.io/apimachinery/pkg/labels"
-	"net"
+	goruntime "runtime"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
-	"sort"
-	"strconv"
 	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -46,16 +51,29 @@ var log = logf.Log.WithName("wavelet.controller")
 // Add creates a new Wavelet Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	health := newHealthChecker(mgr.GetClient(), mgr.GetRecorder("wavelet-controller"))
+
+	if err := mgr.Add(health); err != nil {
+		return err
+	}
+
+	return add(mgr, newReconciler(mgr, health), health.events)
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileWavelet{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+func newReconciler(mgr manager.Manager, health *healthChecker) reconcile.Reconciler {
+	return &ReconcileWavelet{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetRecorder("wavelet-controller"),
+		wallets:  newWalletGenerator(goruntime.NumCPU()),
+		health:   health,
+	}
 }
 
-// add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+// add adds a new Controller to mgr with r as the reconcile.Reconciler. Clusters sent through health are reconciled
+// such that the health observed by the health checker is written into their status.
+func add(mgr manager.Manager, r reconcile.Reconciler, health <-chan event.GenericEvent) error {
 	c, err := controller.New("wavelet-controller", mgr, controller.Options{Reconciler: r})
 
 	if err != nil {
@@ -68,14 +86,65 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch all pods belonging to a cluster so that the status of the cluster is kept up to date. Node pods are owned
+	// by the StatefulSet of a cluster rather than the cluster itself, so pods are mapped back to their cluster by label.
+	err = c.Watch(&source.Kind{Type: new(corev1.Pod)}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(mapPodToCluster)})
+
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: new(appsv1.StatefulSet)}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    new(waveletv1alpha1.Wavelet),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: new(batchv1.Job)}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    new(waveletv1alpha1.Wavelet),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Channel{Source: health}, new(handler.EnqueueRequestForObject))
+
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+func mapPodToCluster(obj handler.MapObject) []reconcile.Request {
+	podLabels := obj.Meta.GetLabels()
+
+	if _, exists := podLabels["role"]; !exists {
+		return nil
+	}
+
+	name, exists := podLabels["app"]
+
+	if !exists {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: obj.Meta.GetNamespace(), Name: name}}}
+}
+
 var _ reconcile.Reconciler = &ReconcileWavelet{}
 
 type ReconcileWavelet struct {
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	wallets  *walletGenerator
+	health   *healthChecker
 }
 
 func (r *ReconcileWavelet) Reconcile(request reconcile.Request) (reconcile.Result, error) {
@@ -85,219 +154,395 @@ func (r *ReconcileWavelet) Reconcile(request reconcile.Request) (reconcile.Resul
 
 	if err := r.client.Get(context.TODO(), request.NamespacedName, cluster); err != nil {
 		if errors.IsNotFound(err) {
+			r.wallets.cancel(request.NamespacedName)
+			r.health.forget(request.NamespacedName)
 			return reconcile.Result{}, nil
 		}
 
 		return reconcile.Result{}, err
 	}
 
-	nodeList := new(corev1.PodList)
+	if cluster.GetDeletionTimestamp() != nil {
+		result, released, err := r.teardown(logger, cluster)
 
-	opts := &client.ListOptions{Namespace: cluster.Namespace, LabelSelector: labels.SelectorFromSet(labelsForWavelet(cluster.Name, "node"))}
+		if released {
+			return result, err
+		}
 
-	if err := r.client.List(context.TODO(), opts, nodeList); err != nil {
-		logger.Error(err, "Failed to list all node pods created by the operator.")
+		if err := r.updateStatus(cluster, err); err != nil {
+			logger.Error(err, "Failed to update the status of the cluster.")
+			return reconcile.Result{}, err
+		}
+
+		return result, err
+	}
+
+	result, err := r.reconcile(logger, cluster)
+
+	if err := r.updateStatus(cluster, err); err != nil {
+		logger.Error(err, "Failed to update the status of the cluster.")
 		return reconcile.Result{}, err
 	}
 
-	var nodePods []corev1.Pod
+	return result, err
+}
+
+// listPods returns all pods of a given role in a cluster that are not in the middle of being deleted.
+func (r *ReconcileWavelet) listPods(cluster *waveletv1alpha1.Wavelet, role string) ([]corev1.Pod, error) {
+	list := new(corev1.PodList)
+
+	opts := &client.ListOptions{Namespace: cluster.Namespace, LabelSelector: labels.SelectorFromSet(LabelsForWavelet(cluster.Name, role))}
+
+	if err := r.client.List(context.TODO(), opts, list); err != nil {
+		return nil, err
+	}
+
+	var pods []corev1.Pod
 
-	for _, pod := range nodeList.Items {
+	for _, pod := range list.Items {
 		if pod.GetObjectMeta().GetDeletionTimestamp() != nil {
 			continue
 		}
 
-		nodePods = append(nodePods, pod)
+		pods = append(pods, pod)
 	}
 
-	benchmarkList := new(corev1.PodList)
+	return pods, nil
+}
+
+// getWalletSecret returns the secret holding the genesis and wallets of a cluster. The secret is generated exactly
+// once when the cluster is first reconciled, and is reused afterwards such that the genesis of the cluster never
+// changes across operator restarts. Wallets are generated in the background, and no secret is returned until they
+// are done being generated.
+func (r *ReconcileWavelet) getWalletSecret(logger logr.Logger, cluster *waveletv1alpha1.Wavelet) (*corev1.Secret, error) {
+	secret := new(corev1.Secret)
 
-	opts = &client.ListOptions{Namespace: cluster.Namespace, LabelSelector: labels.SelectorFromSet(labelsForWavelet(cluster.Name, "benchmark"))}
+	err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: GetWaveletWalletSecretName(cluster)}, secret)
 
-	if err := r.client.List(context.TODO(), opts, benchmarkList); err != nil {
-		logger.Error(err, "Failed to list all benchmark pods created by the operator.")
-		return reconcile.Result{}, err
+	if err == nil {
+		return secret, nil
 	}
 
-	var benchmarkPods []corev1.Pod
+	if !errors.IsNotFound(err) {
+		logger.Error(err, "Failed to query the wallet secret.")
+		return nil, err
+	}
 
-	for _, benchmarkPod := range benchmarkList.Items {
-		if benchmarkPod.GetObjectMeta().GetDeletionTimestamp() != nil {
-			continue
-		}
+	var accounts []waveletv1alpha1.WaveletGenesisAccount
+	var base string
 
-		benchmarkPods = append(benchmarkPods, benchmarkPod)
+	if cluster.Spec.Genesis != nil {
+		accounts = cluster.Spec.Genesis.Accounts
+
+		if base, err = r.getGenesisBase(logger, cluster); err != nil {
+			return nil, err
+		}
 	}
 
-	if cluster.Spec.Size <= 0 {
-		if len(nodePods) > 0 {
-			logger.Info("Deleting all node pods in the cluster.")
+	var generated map[string]wallet
 
-			for _, pod := range nodePods {
-				if err := r.client.Delete(context.TODO(), &pod, client.GracePeriodSeconds(0)); err != nil && !errors.IsNotFound(err) {
-					return reconcile.Result{}, err
-				}
-			}
-		}
+	if keys := getWalletKeys(cluster.Spec.NumRichWallets, getWaveletSize(cluster), accounts); len(keys) > 0 {
+		var done bool
 
-		if len(benchmarkPods) > 0 {
-			logger.Info("Deleting all benchmark pods in the cluster.")
+		generated, done, err = r.wallets.generate(cluster, keys)
 
-			for _, benchmarkPod := range benchmarkPods {
-				if err := r.client.Delete(context.TODO(), &benchmarkPod, client.GracePeriodSeconds(0)); err != nil && !errors.IsNotFound(err) {
-					return reconcile.Result{}, err
-				}
-			}
+		if err != nil {
+			logger.Error(err, "Failed to generate wallets.")
+			return nil, err
 		}
 
-		return reconcile.Result{}, nil
+		if !done {
+			return nil, nil
+		}
 	}
 
-	genesis, err := createGenesis(logger, cluster.Spec.NumRichWallets)
+	genesis, wallets, err := createGenesis(cluster.Spec.NumRichWallets, getWaveletSize(cluster), cluster.Spec.NodeBalance, accounts, base, generated)
 
-	if err != nil || len(genesis) == 0 {
-		return reconcile.Result{}, err
+	if err != nil {
+		logger.Error(err, "Failed to generate genesis.")
+		return nil, err
 	}
 
-	bootstrap := getWaveletBootstrapPod(cluster, genesis)
+	secret = getWaveletWalletSecret(cluster, genesis, wallets)
 
-	if err := controllerutil.SetControllerReference(cluster, bootstrap, r.scheme); err != nil {
-		return reconcile.Result{}, err
+	if err := controllerutil.SetControllerReference(cluster, secret, r.scheme); err != nil {
+		return nil, err
 	}
 
-	if err := r.client.Create(context.TODO(), bootstrap); err != nil {
+	if err := r.client.Create(context.TODO(), secret); err != nil {
 		if !errors.IsAlreadyExists(err) {
-			logger.Error(err, "Failed to create bootstrap pod.")
-			return reconcile.Result{}, err
+			logger.Error(err, "Failed to create the wallet secret.")
+			return nil, err
 		}
-	} else {
-		logger.Info("Creating a single Wavelet pod for other pods to bootstrap to...")
-		return reconcile.Result{Requeue: true}, nil
+
+		// Another reconciliation pass beat us to creating the secret, so use theirs instead.
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: secret.Name}, secret); err != nil {
+			return nil, err
+		}
+
+		return secret, nil
 	}
 
-	if err := r.client.Get(context.TODO(), request.NamespacedName, bootstrap); err != nil {
-		if errors.IsNotFound(err) {
-			return reconcile.Result{}, nil
+	logger.Info("Created the wallet secret.", "secret_name", secret.Name, "num_wallets", len(wallets))
+
+	return secret, nil
+}
+
+// ensureNodeWallets generates a wallet for every node added to a cluster after its genesis, and stores them in the
+// wallet secret of the cluster. It reports whether every node has a wallet, such that no node is started before its
+// wallet exists.
+func (r *ReconcileWavelet) ensureNodeWallets(logger logr.Logger, cluster *waveletv1alpha1.Wavelet, secret *corev1.Secret) (bool, error) {
+	var missing []string
+
+	for _, key := range getNodeWalletKeys(cluster.Spec.NumRichWallets, getWaveletSize(cluster)) {
+		if _, exists := secret.Data[key]; !exists {
+			missing = append(missing, key)
 		}
+	}
 
-		logger.Error(err, "Failed to query details about the bootstrap pod.")
-		return reconcile.Result{}, err
+	if len(missing) == 0 {
+		return true, nil
 	}
 
-	if len(bootstrap.Status.PodIP) == 0 {
-		logger.Info("Waiting for the bootstrap pod to have an IP address assigned...")
-		return reconcile.Result{RequeueAfter: 1 * time.Second}, nil
+	generated, done, err := r.wallets.generate(cluster, missing)
+
+	if err != nil {
+		logger.Error(err, "Failed to generate node wallets.")
+		return false, err
 	}
 
-	if len(nodePods) == 1 {
-		logger.Info("Bootstrap pod is available.", "bootstrap_pod_name", bootstrap.Name, "bootstrap_pod_ip", bootstrap.Status.PodIP)
+	if !done {
+		return false, nil
 	}
 
-	currentNumNode := int32(len(nodePods))
-	expectedNumNodes := cluster.Spec.Size
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
 
-	if currentNumNode > expectedNumNodes { // Scale down number of workers.
-		sort.Slice(nodePods, func(i, j int) bool {
-			ii, _ := strconv.ParseInt(nodePods[i].Name[len(cluster.Name):], 10, 32)
-			jj, _ := strconv.ParseInt(nodePods[j].Name[len(cluster.Name):], 10, 32)
+	for key, w := range generated {
+		secret.Data[key] = w.privateKey
+	}
 
-			return ii > jj
-		})
+	if err := r.client.Update(context.TODO(), secret); err != nil {
+		logger.Error(err, "Failed to store node wallets in the wallet secret.")
+		return false, err
+	}
 
-		for i := currentNumNode; i > expectedNumNodes; i-- {
-			target := nodePods[i-1]
+	logger.Info("Stored node wallets in the wallet secret.", "secret_name", secret.Name, "num_wallets", len(generated))
 
-			if err := r.client.Delete(context.TODO(), &target, client.GracePeriodSeconds(0)); err != nil {
-				logger.Error(err, "Failed to delete worker pod.", "pod_name", target.Name)
-				return reconcile.Result{}, err
-			}
+	return true, nil
+}
 
-			logger.Info("Deleted worker pod.", "pod_name", target.Name)
-		}
+// getGenesisBase returns the genesis JSON file held by the genesis ConfigMap of a cluster, should it have one.
+func (r *ReconcileWavelet) getGenesisBase(logger logr.Logger, cluster *waveletv1alpha1.Wavelet) (string, error) {
+	source := cluster.Spec.Genesis.ConfigMap
 
-		return reconcile.Result{Requeue: true}, nil
+	if source == nil {
+		return "", nil
 	}
 
-	if currentNumNode < expectedNumNodes { // Scale up number of workers.
-		for idx := currentNumNode; idx < expectedNumNodes; idx++ {
-			nodePod := getWaveletNodePod(cluster, genesis, uint(idx), net.JoinHostPort(bootstrap.Status.PodIP, "3000"))
+	key := source.Key
 
-			if err := controllerutil.SetControllerReference(cluster, nodePod, r.scheme); err != nil {
-				return reconcile.Result{}, err
-			}
+	if len(key) == 0 {
+		key = DefaultGenesisConfigMapKey
+	}
+
+	configMap := new(corev1.ConfigMap)
+
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: source.Name}, configMap); err != nil {
+		logger.Error(err, "Failed to query the genesis config map.", "config_map_name", source.Name)
+		return "", err
+	}
 
-			if err := r.client.Create(context.TODO(), nodePod); err != nil && !errors.IsAlreadyExists(err) {
-				logger.Error(err, "Failed to create worker pod.", "idx", idx)
+	genesis, exists := configMap.Data[key]
+
+	if !exists {
+		return "", fmt.Errorf("config map %q has no key %q holding a genesis", source.Name, key)
+	}
+
+	return genesis, nil
+}
+
+func (r *ReconcileWavelet) reconcile(logger logr.Logger, cluster *waveletv1alpha1.Wavelet) (reconcile.Result, error) {
+	if err := r.ensureFinalizer(logger, cluster); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	benchmarkPods, err := r.listPods(cluster, "benchmark")
+
+	if err != nil {
+		logger.Error(err, "Failed to list all benchmark pods created by the operator.")
+		return reconcile.Result{}, err
+	}
+
+	if cluster.Spec.Size <= 0 && len(benchmarkPods) > 0 {
+		logger.Info("Deleting all benchmark pods in the cluster.")
+
+		for _, benchmarkPod := range benchmarkPods {
+			if err := r.client.Delete(context.TODO(), &benchmarkPod); err != nil && !errors.IsNotFound(err) {
 				return reconcile.Result{}, err
 			}
+		}
+
+		benchmarkPods = nil
+	}
+
+	if err := r.deleteLegacyNodePods(logger, cluster); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	secret, err := r.getWalletSecret(logger, cluster)
+
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if secret == nil {
+		logger.Info("Waiting for wallets to be generated...")
+		return reconcile.Result{RequeueAfter: 2 * time.Second}, nil
+	}
+
+	ready, err := r.ensureNodeWallets(logger, cluster, secret)
+
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !ready {
+		logger.Info("Waiting for node wallets to be generated...")
+		return reconcile.Result{RequeueAfter: 2 * time.Second}, nil
+	}
+
+	if err := r.ensureService(logger, cluster); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := r.ensureStatefulSet(logger, cluster, secret); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := r.ensureLedgerRetention(logger, cluster); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if cluster.Spec.Size <= 0 {
+		return reconcile.Result{}, nil
+	}
+
+	nodePods, err := r.listPods(cluster, "node")
+
+	if err != nil {
+		logger.Error(err, "Failed to list all node pods created by the operator.")
+		return reconcile.Result{}, err
+	}
+
+	nodes := make(map[uint]corev1.Pod, len(nodePods))
 
-			logger.Info("Created worker pod.", "pod_name", nodePod.Name)
+	for _, pod := range nodePods {
+		if idx, ok := GetWaveletPodOrdinal(cluster.Name, pod); ok {
+			nodes[idx] = pod
 		}
+	}
+
+	if err := r.labelSeedPods(logger, cluster, nodes); err != nil {
+		return reconcile.Result{}, err
+	}
 
-		return reconcile.Result{Requeue: true}, nil
+	if rolling, err := r.rolloutNodes(logger, cluster, nodes); err != nil || rolling {
+		return reconcile.Result{}, err
 	}
 
-	sort.Slice(nodePods, func(i, j int) bool {
-		ii, _ := strconv.ParseInt(nodePods[i].Name[len(cluster.Name):], 10, 32)
-		jj, _ := strconv.ParseInt(nodePods[j].Name[len(cluster.Name):], 10, 32)
+	if err := r.releaseWipedLedgers(logger, cluster, nodes); err != nil {
+		return reconcile.Result{}, err
+	}
 
-		return ii > jj
-	})
+	if remediated, err := r.remediateNodes(logger, cluster, nodes); err != nil || remediated {
+		return reconcile.Result{}, err
+	}
 
-	for i, nodePod := range nodePods {
-		if len(nodePod.Status.PodIP) == 0 {
-			logger.Info("Waiting for pod to be ready before initializing benchmark nodes...", "pod_name", nodePod.Name, "pod_idx", i, "pod_status", nodePod.Status.Phase)
+	for idx := uint(0); idx < uint(cluster.Spec.Size); idx++ {
+		nodePod, exists := nodes[idx]
+
+		if !exists {
+			logger.Info("Waiting for the StatefulSet to create all node pods...", "pod_idx", idx, "num_nodes", len(nodes), "cluster_size", cluster.Spec.Size)
+			return reconcile.Result{}, nil
+		}
+
+		if !IsNodeAvailable(cluster, nodePod) {
+			logger.Info("Waiting for pod to be ready and healthy before initializing benchmark nodes...", "pod_name", nodePod.Name, "pod_idx", idx, "pod_status", nodePod.Status.Phase)
 			return reconcile.Result{RequeueAfter: 1 * time.Second}, nil
 		}
 	}
 
-	currentNumBenchmarkPods := uint(len(benchmarkPods))
-	expectedNumBenchmarkPods := cluster.Spec.NumBenchmarkPods
+	targets, err := GetWaveletBenchmarkTargets(cluster.Spec.BenchmarkTarget, cluster.Name, cluster.Spec.NumBenchmarkPods, uint(cluster.Spec.Size), nodes)
 
-	if expectedNumBenchmarkPods > uint(len(nodePods)) {
-		logger.Info("There must always be equal to or less benchmark pods than node pods in the cluster. Please reconfigure your cluster.", "expected_num_benchmark_pods", expectedNumBenchmarkPods, "cluster_size", len(nodePods))
-		return reconcile.Result{}, nil
+	if err != nil {
+		logger.Error(err, "Failed to assign benchmark pods to nodes.")
+		return reconcile.Result{}, err
 	}
 
-	if currentNumBenchmarkPods > expectedNumBenchmarkPods {
-		sort.Slice(benchmarkPods, func(i, j int) bool {
-			ii, _ := strconv.ParseInt(benchmarkPods[i].Name[len(cluster.Name)+len("-benchmark"):], 10, 32)
-			jj, _ := strconv.ParseInt(benchmarkPods[j].Name[len(cluster.Name)+len("-benchmark"):], 10, 32)
+	if len(targets) == 0 && cluster.Spec.NumBenchmarkPods > 0 {
+		logger.Info("No nodes match the benchmark target of the cluster.", "expected_num_benchmark_pods", cluster.Spec.NumBenchmarkPods)
+	}
 
-			return ii > jj
-		})
+	expectedNumBenchmarkPods := uint(len(targets))
 
-		for i := currentNumBenchmarkPods; i > expectedNumBenchmarkPods; i-- {
-			target := benchmarkPods[i-1]
+	// Benchmark pods are only rendered once the wallet they sign transactions with has been generated.
+	desiredBenchmarkPod := func(idx uint) *corev1.Pod {
+		wallet, exists := GetWaveletBenchmarkWallet(cluster.Spec.BenchmarkTarget, secret, idx, targets[idx], uint(cluster.Spec.Size))
 
-			if err := r.client.Delete(context.TODO(), &target, client.GracePeriodSeconds(0)); err != nil {
-				logger.Error(err, "Failed to delete benchmark pod.", "pod_name", target.Name)
-				return reconcile.Result{}, err
+		if !exists {
+			return nil
+		}
+
+		return getWaveletBenchmarkPod(cluster, targets[idx], wallet, idx)
+	}
+
+	benchmarks := make(map[uint]struct{}, len(benchmarkPods))
+
+	// Benchmark pods are not managed by a controller that rolls them out, so benchmark pods that drifted from the
+	// spec they would be rendered with today (i.e. due to a change in image, or being assigned another node) are
+	// deleted and recreated.
+	for _, benchmarkPod := range benchmarkPods {
+		if idx, ok := GetWaveletPodOrdinal(cluster.Name+"-benchmark", benchmarkPod); ok && idx < expectedNumBenchmarkPods {
+			desired := desiredBenchmarkPod(idx)
+
+			if desired != nil && benchmarkPod.Annotations[AnnotationSpecHash] == desired.Annotations[AnnotationSpecHash] {
+				benchmarks[idx] = struct{}{}
+				continue
 			}
+		}
 
-			logger.Info("Deleted benchmark pod.", "pod_name", target.Name)
+		if err := r.client.Delete(context.TODO(), &benchmarkPod); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete benchmark pod.", "pod_name", benchmarkPod.Name)
+			return reconcile.Result{}, err
 		}
 
-		return reconcile.Result{Requeue: true}, nil
+		logger.Info("Deleted benchmark pod.", "pod_name", benchmarkPod.Name)
 	}
 
-	if currentNumBenchmarkPods < expectedNumBenchmarkPods {
-		for idx := currentNumBenchmarkPods; idx < expectedNumBenchmarkPods; idx++ {
-			benchmarkPod := getWaveletBenchmarkPod(cluster, nodePods[idx])
+	for idx := uint(0); idx < expectedNumBenchmarkPods; idx++ {
+		if _, exists := benchmarks[idx]; exists {
+			continue
+		}
 
-			if err := controllerutil.SetControllerReference(cluster, benchmarkPod, r.scheme); err != nil {
-				return reconcile.Result{}, err
-			}
+		pod := desiredBenchmarkPod(idx)
 
-			if err := r.client.Create(context.TODO(), benchmarkPod); err != nil && !errors.IsAlreadyExists(err) {
-				logger.Error(err, "Failed to create benchmark pod.", "idx", idx)
-				return reconcile.Result{}, err
-			}
+		if pod == nil {
+			logger.Info("Waiting for the wallet of benchmark pod to be generated...", "idx", idx, "node_idx", targets[idx])
+			continue
+		}
 
-			logger.Info("Created benchmark pod.", "pod_name", benchmarkPod.Name)
+		if err := controllerutil.SetControllerReference(cluster, pod, r.scheme); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		if err := r.client.Create(context.TODO(), pod); err != nil && !errors.IsAlreadyExists(err) {
+			logger.Error(err, "Failed to create benchmark pod.", "idx", idx)
+			return reconcile.Result{}, err
 		}
 
-		return reconcile.Result{Requeue: true}, nil
+		logger.Info("Created benchmark pod.", "pod_name", pod.Name, "node_idx", targets[idx])
 	}
 
 	return reconcile.Result{}, nil
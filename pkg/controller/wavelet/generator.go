@@ -0,0 +1,288 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"errors"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"sync"
+	"sync/atomic"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var errWalletGenerationCancelled = errors.New("wallet generation was cancelled")
+
+// walletGenerator generates the wallets of clusters in the background, such that generating keys at a high puzzle
+// difficulty never stalls the reconcile loop. Wallets of all clusters are generated by a single pool of a bounded
+// number of workers.
+type walletGenerator struct {
+	workers chan struct{}
+
+	lock sync.Mutex
+	jobs map[types.NamespacedName]*walletJob
+}
+
+// walletJob generates all wallets of a single cluster. Keys requested while the job is running are added to the
+// keys it generates, such that resizing a cluster while its wallets are generated neither restarts nor fails the job.
+type walletJob struct {
+	uid       types.UID
+	total     int32 // atomic
+	generated int32 // atomic
+
+	stop chan struct{}
+	done chan struct{}
+
+	lock       sync.Mutex
+	keys       map[string]struct{}
+	pending    []string
+	dispatched bool
+	wallets    map[string]wallet
+	err        error
+}
+
+func newWalletGenerator(workers int) *walletGenerator {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &walletGenerator{
+		workers: make(chan struct{}, workers),
+		jobs:    make(map[types.NamespacedName]*walletJob),
+	}
+}
+
+// generate starts generating the wallets stored under keys for a cluster should they not already be being generated,
+// and reports whether they are done being generated. Keys missing from a job already running for the cluster are
+// added to it. Once done, the wallets stored under keys are returned and the job is forgotten by the generator.
+func (g *walletGenerator) generate(cluster *waveletv1alpha1.Wavelet, keys []string) (map[string]wallet, bool, error) {
+	name := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	job, exists := g.jobs[name]
+
+	// Wallets being generated for a previous cluster of the same name are of no use to the current one.
+	if exists && job.uid != cluster.UID {
+		close(job.stop)
+		exists = false
+	}
+
+	if !exists {
+		g.jobs[name] = g.start(cluster, keys, nil)
+		return nil, false, nil
+	}
+
+	extended := job.extend(keys)
+
+	select {
+	case <-job.done:
+	default:
+		return nil, false, nil
+	}
+
+	if job.err != nil {
+		delete(g.jobs, name)
+		return nil, true, job.err
+	}
+
+	// Keys requested after the job handed out its last key are generated by a new job, which picks up all wallets
+	// generated so far.
+	if !extended {
+		g.jobs[name] = g.start(cluster, keys, job.wallets)
+		return nil, false, nil
+	}
+
+	delete(g.jobs, name)
+
+	wallets := make(map[string]wallet, len(keys))
+
+	for _, key := range keys {
+		wallets[key] = job.wallets[key]
+	}
+
+	return wallets, true, nil
+}
+
+// extend adds keys to the keys generated by a job, and reports whether the job generates all of them. Keys can no
+// longer be added once the job handed out its last key to a worker.
+func (j *walletJob) extend(keys []string) bool {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	for _, key := range keys {
+		if _, exists := j.keys[key]; exists {
+			continue
+		}
+
+		if j.dispatched {
+			return false
+		}
+
+		j.keys[key] = struct{}{}
+		j.pending = append(j.pending, key)
+
+		atomic.AddInt32(&j.total, 1)
+	}
+
+	return true
+}
+
+// next returns the next key to be generated by a job, should there be one left. The job may not be extended once
+// there is none left.
+func (j *walletJob) next() (string, bool) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if len(j.pending) == 0 {
+		j.dispatched = true
+		return "", false
+	}
+
+	key := j.pending[0]
+	j.pending = j.pending[1:]
+
+	return key, true
+}
+
+// start starts a job generating the wallets stored under keys for a cluster. Wallets already generated by a previous
+// job are carried over rather than generated again.
+func (g *walletGenerator) start(cluster *waveletv1alpha1.Wavelet, keys []string, generated map[string]wallet) *walletJob {
+	logger := log.WithValues("request.namespace", cluster.Namespace, "request.name", cluster.Name)
+
+	seed := cluster.Spec.WalletSeed
+	c1, c2 := getWaveletPuzzle(cluster)
+
+	job := &walletJob{
+		uid:     cluster.UID,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		keys:    make(map[string]struct{}, len(keys)),
+		wallets: make(map[string]wallet, len(keys)),
+	}
+
+	for _, key := range keys {
+		if _, exists := job.keys[key]; exists {
+			continue
+		}
+
+		job.keys[key] = struct{}{}
+		job.total++
+
+		if w, exists := generated[key]; exists {
+			job.wallets[key] = w
+			job.generated++
+
+			continue
+		}
+
+		job.pending = append(job.pending, key)
+	}
+
+	logger.Info("Generating wallets...", "num_wallets", len(job.pending), "c1", c1, "c2", c2)
+
+	go func() {
+		defer close(job.done)
+
+		var wg sync.WaitGroup
+
+		for {
+			key, exists := job.next()
+
+			if !exists {
+				break
+			}
+
+			select {
+			case <-job.stop:
+				job.lock.Lock()
+				job.err = errWalletGenerationCancelled
+				job.dispatched = true
+				job.lock.Unlock()
+
+				wg.Wait()
+
+				return
+			case g.workers <- struct{}{}:
+			}
+
+			wg.Add(1)
+
+			go func(key string) {
+				defer func() {
+					<-g.workers
+					wg.Done()
+				}()
+
+				w, err := generateWallet(seed, key, c1, c2)
+
+				job.lock.Lock()
+				defer job.lock.Unlock()
+
+				if err != nil {
+					if job.err == nil {
+						job.err = err
+					}
+
+					return
+				}
+
+				job.wallets[key] = w
+				atomic.AddInt32(&job.generated, 1)
+
+				logger.Info("Generated a wallet.", "key", key)
+			}(key)
+		}
+
+		wg.Wait()
+	}()
+
+	return job
+}
+
+// progress reports how many wallets of a cluster have been generated, should they be being generated.
+func (g *walletGenerator) progress(cluster *waveletv1alpha1.Wavelet) *waveletv1alpha1.WaveletWalletGenerationStatus {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	job, exists := g.jobs[types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}]
+
+	if !exists || job.uid != cluster.UID {
+		return nil
+	}
+
+	return &waveletv1alpha1.WaveletWalletGenerationStatus{
+		Generated: atomic.LoadInt32(&job.generated),
+		Total:     atomic.LoadInt32(&job.total),
+	}
+}
+
+// cancel stops generating the wallets of a cluster. Wallets that are already being generated by a worker are run to
+// completion and discarded.
+func (g *walletGenerator) cancel(name types.NamespacedName) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if job, exists := g.jobs[name]; exists {
+		close(job.stop)
+		delete(g.jobs, name)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newGeneratorTestCluster() *waveletv1alpha1.Wavelet {
+	return &waveletv1alpha1.Wavelet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: "uid"},
+		Spec:       waveletv1alpha1.WaveletSpec{Puzzle: waveletv1alpha1.WaveletPuzzleSpec{C1: 1, C2: 1}},
+	}
+}
+
+// waitForWallets requests keys from a generator until they are done being generated.
+func waitForWallets(t *testing.T, g *walletGenerator, cluster *waveletv1alpha1.Wavelet, keys []string) map[string]wallet {
+	deadline := time.Now().Add(10 * time.Second)
+
+	for time.Now().Before(deadline) {
+		wallets, done, err := g.generate(cluster, keys)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if done {
+			return wallets
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("timed out waiting for wallets %v", keys)
+
+	return nil
+}
+
+func TestWalletGeneratorExtendsRunningJob(t *testing.T) {
+	g := newWalletGenerator(1)
+	cluster := newGeneratorTestCluster()
+
+	// Occupy the only worker, such that the job can not hand out its keys.
+	g.workers <- struct{}{}
+
+	if _, done, _ := g.generate(cluster, []string{nodeSecretKey(1), nodeSecretKey(2)}); done {
+		t.Fatal("expected wallets to be generated in the background")
+	}
+
+	if _, done, _ := g.generate(cluster, []string{nodeSecretKey(1), nodeSecretKey(2), nodeSecretKey(3)}); done {
+		t.Fatal("expected wallets to be generated in the background")
+	}
+
+	if progress := g.progress(cluster); progress == nil || progress.Total != 3 {
+		t.Fatalf("expected the running job to be extended to 3 wallets, got %+v", progress)
+	}
+
+	<-g.workers
+
+	wallets := waitForWallets(t, g, cluster, []string{nodeSecretKey(1), nodeSecretKey(2), nodeSecretKey(3)})
+
+	if len(wallets) != 3 {
+		t.Fatalf("expected 3 wallets, got %d", len(wallets))
+	}
+
+	if progress := g.progress(cluster); progress != nil {
+		t.Errorf("expected the job to be forgotten once done, got %+v", progress)
+	}
+}
+
+func TestWalletGeneratorReturnsRequestedKeys(t *testing.T) {
+	g := newWalletGenerator(1)
+	cluster := newGeneratorTestCluster()
+
+	g.workers <- struct{}{}
+
+	g.generate(cluster, []string{nodeSecretKey(1), nodeSecretKey(2), nodeSecretKey(3)})
+
+	<-g.workers
+
+	wallets := waitForWallets(t, g, cluster, []string{nodeSecretKey(1)})
+
+	if _, exists := wallets[nodeSecretKey(1)]; len(wallets) != 1 || !exists {
+		t.Errorf("expected only the wallet of node 1 after shrinking, got %d wallets", len(wallets))
+	}
+}
+
+func TestWalletGeneratorCarriesOverFinishedJob(t *testing.T) {
+	g := newWalletGenerator(1)
+	cluster := newGeneratorTestCluster()
+
+	g.generate(cluster, []string{nodeSecretKey(1)})
+
+	job := g.jobs[types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}]
+	<-job.done
+
+	first := job.wallets[nodeSecretKey(1)]
+
+	// The finished job can no longer be extended, so a new job generates the missing key.
+	if _, done, _ := g.generate(cluster, []string{nodeSecretKey(1), nodeSecretKey(2)}); done {
+		t.Fatal("expected the missing wallet to be generated in the background")
+	}
+
+	if progress := g.progress(cluster); progress == nil || progress.Total != 2 || progress.Generated < 1 {
+		t.Fatalf("expected the new job to carry over 1 of 2 wallets, got %+v", progress)
+	}
+
+	wallets := waitForWallets(t, g, cluster, []string{nodeSecretKey(1), nodeSecretKey(2)})
+
+	if len(wallets) != 2 {
+		t.Fatalf("expected 2 wallets, got %d", len(wallets))
+	}
+
+	if string(wallets[nodeSecretKey(1)].privateKey) != string(first.privateKey) {
+		t.Error("expected the wallet of node 1 to be carried over rather than generated again")
+	}
+}
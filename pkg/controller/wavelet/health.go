@@ -0,0 +1,563 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"net"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+// HealthCheckInterval is the interval at which the operator queries the ledger state of every running node.
+const HealthCheckInterval = 15 * time.Second
+
+// DefaultHealthMaxRoundLag, DefaultHealthMinPeers, DefaultHealthStuckSeconds and DefaultHealthStallSeconds configure
+// the health checks of a cluster should it leave them unset.
+const (
+	DefaultHealthMaxRoundLag  = 10
+	DefaultHealthMinPeers     = 1
+	DefaultHealthStuckSeconds = 120
+	DefaultHealthStallSeconds = 300
+)
+
+const (
+	healthCheckTimeout     = 5 * time.Second
+	healthCheckConcurrency = 32
+)
+
+// nodeLedger is the subset of the response of WaveletLedgerPath the health checker relies on.
+type nodeLedger struct {
+	Round struct {
+		Index uint64 `json:"index"`
+
+		// MerkleRoot is the root of the ledger state the round was finalized with.
+		MerkleRoot string `json:"merkle_root"`
+
+		// EndID is the ID of the transaction that finalized the round, which is the last transaction the node
+		// accepted.
+		EndID string `json:"end_id"`
+	} `json:"round"`
+
+	Peers []struct {
+		Address string `json:"address"`
+	} `json:"peers"`
+
+	// SyncStatus describes whether the ledger of the node is in sync with its peers, and reads "Node is out of sync"
+	// while it is not.
+	SyncStatus string `json:"sync_status"`
+}
+
+// isOutOfSync reports whether a node reports its ledger to be out of sync with its peers.
+func (l nodeLedger) isOutOfSync() bool {
+	return strings.Contains(strings.ToLower(l.SyncStatus), "out of sync")
+}
+
+// nodeHealth is the ledger state of a node as last queried by the health checker.
+type nodeHealth struct {
+	uid    types.UID
+	status waveletv1alpha1.WaveletNodeHealthStatus
+}
+
+// clusterHealth is the health of a cluster as last observed by the health checker.
+type clusterHealth struct {
+	uid types.UID
+
+	// round is the median round of all nodes that responded, and roundSince is the time it last advanced while
+	// benchmarks ran against the cluster.
+	round      uint64
+	roundSince time.Time
+
+	// agreeing is the number of healthy nodes out of checked nodes.
+	agreeing, checked int
+
+	partitionedSince time.Time
+	partitioned      bool
+	stalled          bool
+
+	nodes map[uint]nodeHealth
+}
+
+// getStuckNodes returns the names of all nodes that are stuck, in order of their ordinal.
+func (h *clusterHealth) getStuckNodes() []string {
+	var stuck []string
+
+	for _, node := range h.getNodeStatuses() {
+		if node.Stuck {
+			stuck = append(stuck, node.Node)
+		}
+	}
+
+	return stuck
+}
+
+// getNodeStatuses returns the health of each node, in order of their ordinal.
+func (h *clusterHealth) getNodeStatuses() []waveletv1alpha1.WaveletNodeHealthStatus {
+	ordinals := make([]uint, 0, len(h.nodes))
+
+	for idx := range h.nodes {
+		ordinals = append(ordinals, idx)
+	}
+
+	sort.Slice(ordinals, func(i, j int) bool { return ordinals[i] < ordinals[j] })
+
+	statuses := make([]waveletv1alpha1.WaveletNodeHealthStatus, 0, len(ordinals))
+
+	for _, idx := range ordinals {
+		statuses = append(statuses, h.nodes[idx].status)
+	}
+
+	return statuses
+}
+
+// healthChecker periodically queries the HTTP API of every running node of every cluster, and judges whether nodes
+// are stuck behind the rest of their cluster and whether clusters are partitioned or stalled. Clusters whose health
+// changed are sent to the wavelet controller through events, such that their status is brought up to date.
+type healthChecker struct {
+	client   client.Client
+	recorder record.EventRecorder
+	http     *http.Client
+
+	events chan event.GenericEvent
+
+	lock     sync.Mutex
+	clusters map[types.NamespacedName]*clusterHealth
+}
+
+func newHealthChecker(c client.Client, recorder record.EventRecorder) *healthChecker {
+	return &healthChecker{
+		client:   c,
+		recorder: recorder,
+		http:     &http.Client{Timeout: healthCheckTimeout},
+		events:   make(chan event.GenericEvent),
+		clusters: make(map[types.NamespacedName]*clusterHealth),
+	}
+}
+
+// Start implements manager.Runnable, and checks the health of all clusters every HealthCheckInterval until stop is
+// closed.
+func (h *healthChecker) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+		}
+
+		if err := h.checkAll(stop); err != nil {
+			log.Error(err, "Failed to check the health of all clusters.")
+		}
+	}
+}
+
+// get returns the last observed health of a cluster, should it have been checked yet.
+func (h *healthChecker) get(cluster *waveletv1alpha1.Wavelet) (clusterHealth, bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	health, exists := h.clusters[types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}]
+
+	if !exists || health.uid != cluster.UID {
+		return clusterHealth{}, false
+	}
+
+	return *health, true
+}
+
+// hasStuckNodes reports whether any node of a cluster was stuck when it was last checked.
+func (h *healthChecker) hasStuckNodes(name types.NamespacedName) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	health, exists := h.clusters[name]
+
+	return exists && len(health.getStuckNodes()) > 0
+}
+
+// forget drops the last observed health of a cluster that no longer exists.
+func (h *healthChecker) forget(name types.NamespacedName) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	delete(h.clusters, name)
+}
+
+func (h *healthChecker) checkAll(stop <-chan struct{}) error {
+	clusters := new(waveletv1alpha1.WaveletList)
+
+	if err := h.client.List(context.TODO(), &client.ListOptions{}, clusters); err != nil {
+		return err
+	}
+
+	benchmarks := new(waveletv1alpha1.WaveletBenchmarkList)
+
+	if err := h.client.List(context.TODO(), &client.ListOptions{}, benchmarks); err != nil {
+		return err
+	}
+
+	// Clusters are only expected to make progress while benchmarks run against them.
+	loaded := make(map[types.NamespacedName]bool)
+
+	for _, benchmark := range benchmarks.Items {
+		if benchmark.Status.Phase == waveletv1alpha1.WaveletBenchmarkRunning {
+			loaded[types.NamespacedName{Namespace: benchmark.Namespace, Name: benchmark.Spec.Cluster}] = true
+		}
+	}
+
+	seen := make(map[types.NamespacedName]struct{}, len(clusters.Items))
+
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		name := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+
+		if cluster.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		seen[name] = struct{}{}
+
+		changed, err := h.check(cluster, loaded[name] || cluster.Status.BenchmarkPods > 0)
+
+		if err != nil {
+			log.Error(err, "Failed to check the health of the cluster.", "request.namespace", cluster.Namespace, "request.name", cluster.Name)
+			continue
+		}
+
+		// Clusters with stuck nodes are reconciled after every check, such that their nodes are remediated as soon
+		// as their remediation backoff allows.
+		if !changed && !h.hasStuckNodes(name) {
+			continue
+		}
+
+		select {
+		case h.events <- event.GenericEvent{Meta: cluster, Object: cluster}:
+		case <-stop:
+			return nil
+		}
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for name := range h.clusters {
+		if _, exists := seen[name]; !exists {
+			delete(h.clusters, name)
+		}
+	}
+
+	return nil
+}
+
+// check queries the ledger state of every running node of a cluster, and reports whether the health of the cluster
+// changed since it was last checked. loaded reports whether benchmarks are running against the cluster.
+func (h *healthChecker) check(cluster *waveletv1alpha1.Wavelet, loaded bool) (bool, error) {
+	list := new(corev1.PodList)
+
+	opts := &client.ListOptions{Namespace: cluster.Namespace, LabelSelector: labels.SelectorFromSet(LabelsForWavelet(cluster.Name, "node"))}
+
+	if err := h.client.List(context.TODO(), opts, list); err != nil {
+		return false, err
+	}
+
+	pods := make(map[uint]corev1.Pod, len(list.Items))
+
+	for _, pod := range list.Items {
+		if pod.GetDeletionTimestamp() != nil || pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+
+		if idx, ok := GetWaveletPodOrdinal(cluster.Name, pod); ok {
+			pods[idx] = pod
+		}
+	}
+
+	ledgers, errs := h.queryLedgers(pods)
+
+	h.lock.Lock()
+	previous, exists := h.clusters[types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}]
+	h.lock.Unlock()
+
+	if !exists || previous.uid != cluster.UID {
+		previous = &clusterHealth{uid: cluster.UID, roundSince: time.Now()}
+	}
+
+	current := judgeClusterHealth(cluster, previous, pods, ledgers, errs, loaded, time.Now())
+
+	h.recordEvents(cluster, previous, current)
+
+	h.lock.Lock()
+	h.clusters[types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}] = current
+	h.lock.Unlock()
+
+	changed := current.round != previous.round ||
+		current.partitioned != previous.partitioned ||
+		current.stalled != previous.stalled ||
+		!reflect.DeepEqual(current.getNodeStatuses(), previous.getNodeStatuses())
+
+	return changed, nil
+}
+
+// queryLedgers queries the ledger state of a number of node pods keyed by their ordinal in parallel.
+func (h *healthChecker) queryLedgers(pods map[uint]corev1.Pod) (map[uint]nodeLedger, map[uint]error) {
+	ledgers := make(map[uint]nodeLedger, len(pods))
+	errs := make(map[uint]error)
+
+	var (
+		lock sync.Mutex
+		wg   sync.WaitGroup
+	)
+
+	workers := make(chan struct{}, healthCheckConcurrency)
+
+	for idx, pod := range pods {
+		wg.Add(1)
+		workers <- struct{}{}
+
+		go func(idx uint, pod corev1.Pod) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+
+			ledger, err := h.queryLedger(pod)
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			if err != nil {
+				errs[idx] = err
+				return
+			}
+
+			ledgers[idx] = ledger
+		}(idx, pod)
+	}
+
+	wg.Wait()
+
+	return ledgers, errs
+}
+
+func (h *healthChecker) queryLedger(pod corev1.Pod) (nodeLedger, error) {
+	var ledger nodeLedger
+
+	res, err := h.http.Get("http://" + net.JoinHostPort(pod.Status.PodIP, "9000") + WaveletLedgerPath)
+
+	if err != nil {
+		return ledger, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return ledger, fmt.Errorf("%s responded with status %s", WaveletLedgerPath, res.Status)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&ledger); err != nil {
+		return ledger, fmt.Errorf("failed to decode the response of %s: %v", WaveletLedgerPath, err)
+	}
+
+	return ledger, nil
+}
+
+// getWaveletHealthThresholds returns the maximum round lag, the minimum number of peers, and the stuck and stall
+// thresholds of a cluster.
+func getWaveletHealthThresholds(cluster *waveletv1alpha1.Wavelet) (uint64, int32, time.Duration, time.Duration) {
+	health := cluster.Spec.Health
+
+	if health.MaxRoundLag == 0 {
+		health.MaxRoundLag = DefaultHealthMaxRoundLag
+	}
+
+	if health.MinPeers == 0 {
+		health.MinPeers = DefaultHealthMinPeers
+	}
+
+	if health.StuckSeconds == 0 {
+		health.StuckSeconds = DefaultHealthStuckSeconds
+	}
+
+	if health.StallSeconds == 0 {
+		health.StallSeconds = DefaultHealthStallSeconds
+	}
+
+	return uint64(health.MaxRoundLag), health.MinPeers, time.Duration(health.StuckSeconds) * time.Second, time.Duration(health.StallSeconds) * time.Second
+}
+
+// judgeClusterHealth judges the health of a cluster and each of its running nodes at a given time, given the ledgers
+// of nodes that responded and the errors of nodes that did not. Nodes remember the time they entered their current
+// state, such that nodes are only considered stuck and the cluster is only considered partitioned once they have
+// been in a bad state for long enough.
+func judgeClusterHealth(cluster *waveletv1alpha1.Wavelet, previous *clusterHealth, pods map[uint]corev1.Pod, ledgers map[uint]nodeLedger, errs map[uint]error, loaded bool, now time.Time) *clusterHealth {
+	maxRoundLag, minPeers, stuckAfter, stallAfter := getWaveletHealthThresholds(cluster)
+
+	current := &clusterHealth{
+		uid:              cluster.UID,
+		round:            previous.round,
+		roundSince:       previous.roundSince,
+		partitionedSince: previous.partitionedSince,
+		checked:          len(pods),
+		nodes:            make(map[uint]nodeHealth, len(pods)),
+	}
+
+	if len(ledgers) > 0 {
+		rounds := make([]uint64, 0, len(ledgers))
+
+		for _, ledger := range ledgers {
+			rounds = append(rounds, ledger.Round.Index)
+		}
+
+		sort.Slice(rounds, func(i, j int) bool { return rounds[i] < rounds[j] })
+
+		current.round = rounds[len(rounds)/2]
+	}
+
+	// Nodes that finalized the median round are expected to agree on its merkle root. The merkle root of most of them
+	// is taken to be the canonical one, should there be such a majority.
+	roots := make(map[string]int)
+	numRoots := 0
+
+	for _, ledger := range ledgers {
+		if ledger.Round.Index == current.round && len(ledger.Round.MerkleRoot) > 0 {
+			roots[ledger.Round.MerkleRoot]++
+			numRoots++
+		}
+	}
+
+	var root string
+
+	for candidate, count := range roots {
+		if count*2 > numRoots {
+			root = candidate
+		}
+	}
+
+	for idx, pod := range pods {
+		status := waveletv1alpha1.WaveletNodeHealthStatus{Node: pod.Name, State: waveletv1alpha1.WaveletNodeHealthy, UID: pod.UID}
+
+		if ledger, exists := ledgers[idx]; exists {
+			status.Round = ledger.Round.Index
+			status.Peers = int32(len(ledger.Peers))
+			status.LastTransaction = ledger.Round.EndID
+
+			switch {
+			case status.Peers < minPeers && cluster.Spec.Size > 1:
+				status.State = waveletv1alpha1.WaveletNodeIsolated
+				status.Message = fmt.Sprintf("The node is connected to %d/%d peers.", status.Peers, minPeers)
+			case len(root) > 0 && status.Round == current.round && len(ledger.Round.MerkleRoot) > 0 && ledger.Round.MerkleRoot != root:
+				status.State = waveletv1alpha1.WaveletNodeForked
+				status.Message = fmt.Sprintf("The node finalized round %d with merkle root %s rather than %s.", status.Round, ledger.Round.MerkleRoot, root)
+			case status.Round+maxRoundLag < current.round:
+				status.State = waveletv1alpha1.WaveletNodeLagging
+				status.Message = fmt.Sprintf("The node is %d rounds behind round %d.", current.round-status.Round, current.round)
+			case ledger.isOutOfSync():
+				status.State = waveletv1alpha1.WaveletNodeOutOfSync
+				status.Message = fmt.Sprintf("The node reports %q.", ledger.SyncStatus)
+			}
+		} else {
+			status.State = waveletv1alpha1.WaveletNodeUnreachable
+			status.Message = errs[idx].Error()
+		}
+
+		// Times in status are serialized with a precision of a second.
+		status.Since = metav1.NewTime(now.Truncate(time.Second))
+
+		if node, exists := previous.nodes[idx]; exists && node.uid == pod.UID && node.status.State == status.State {
+			status.Since = node.status.Since
+		}
+
+		status.Stuck = status.State != waveletv1alpha1.WaveletNodeHealthy && now.Sub(status.Since.Time) >= stuckAfter
+
+		if status.State == waveletv1alpha1.WaveletNodeHealthy {
+			current.agreeing++
+		}
+
+		current.nodes[idx] = nodeHealth{uid: pod.UID, status: status}
+	}
+
+	// The cluster is partitioned should no majority of its running nodes be healthy.
+	if current.checked > 1 && current.agreeing*2 <= current.checked {
+		if current.partitionedSince.IsZero() {
+			current.partitionedSince = now
+		}
+
+		current.partitioned = now.Sub(current.partitionedSince) >= stuckAfter
+	} else {
+		current.partitionedSince = time.Time{}
+	}
+
+	// The cluster is stalled should its round not advance while benchmarks are running against it. Time spent
+	// without any benchmarks running does not count towards stalling.
+	if !loaded || current.round > previous.round {
+		current.roundSince = now
+	}
+
+	current.stalled = loaded && now.Sub(current.roundSince) >= stallAfter
+
+	return current
+}
+
+// recordEvents emits events for nodes becoming stuck or recovering, and for the cluster becoming or ceasing to be
+// partitioned or stalled.
+func (h *healthChecker) recordEvents(cluster *waveletv1alpha1.Wavelet, previous, current *clusterHealth) {
+	for idx, node := range current.nodes {
+		before, existed := previous.nodes[idx]
+		wasStuck := existed && before.uid == node.uid && before.status.Stuck
+
+		switch {
+		case node.status.Stuck && !wasStuck:
+			h.recorder.Eventf(cluster, corev1.EventTypeWarning, "NodeStuck", "Node %s has been %s since %s: %s", node.status.Node, node.status.State, node.status.Since.UTC().Format(time.RFC3339), node.status.Message)
+		case !node.status.Stuck && wasStuck:
+			h.recorder.Eventf(cluster, corev1.EventTypeNormal, "NodeRecovered", "Node %s is %s.", node.status.Node, node.status.State)
+		}
+	}
+
+	switch {
+	case current.partitioned && !previous.partitioned:
+		h.recorder.Eventf(cluster, corev1.EventTypeWarning, "Partitioned", "Only %d/%d nodes agree on round %d.", current.agreeing, current.checked, current.round)
+	case !current.partitioned && previous.partitioned:
+		h.recorder.Eventf(cluster, corev1.EventTypeNormal, "PartitionHealed", "%d/%d nodes agree on round %d.", current.agreeing, current.checked, current.round)
+	}
+
+	switch {
+	case current.stalled && !previous.stalled:
+		h.recorder.Eventf(cluster, corev1.EventTypeWarning, "Stalled", "Round %d has not advanced since %s while benchmarks are running.", current.round, current.roundSince.UTC().Format(time.RFC3339))
+	case !current.stalled && previous.stalled:
+		h.recorder.Eventf(cluster, corev1.EventTypeNormal, "Resumed", "The cluster advanced to round %d.", current.round)
+	}
+}
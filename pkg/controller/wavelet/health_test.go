@@ -0,0 +1,476 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"errors"
+	"fmt"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// healthTestNode is the ledger state reported by a node in a health check, or the lack thereof.
+type healthTestNode struct {
+	round       uint64
+	root        string
+	peers       int
+	sync        string
+	unreachable bool
+}
+
+func reports(round uint64, root string) healthTestNode {
+	return healthTestNode{round: round, root: root, peers: 2}
+}
+
+func newHealthTestCluster(size int32) *waveletv1alpha1.Wavelet {
+	return &waveletv1alpha1.Wavelet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", UID: "uid"},
+		Spec:       waveletv1alpha1.WaveletSpec{Size: size},
+	}
+}
+
+// newHealthTestInputs returns the pods of a number of nodes keyed by their ordinal, alongside the ledgers they
+// responded with and the errors of those that were unreachable.
+func newHealthTestInputs(nodes []healthTestNode) (map[uint]corev1.Pod, map[uint]nodeLedger, map[uint]error) {
+	pods := make(map[uint]corev1.Pod, len(nodes))
+	ledgers := make(map[uint]nodeLedger)
+	errs := make(map[uint]error)
+
+	for i, node := range nodes {
+		idx := uint(i)
+		name := fmt.Sprintf("test-%d", idx)
+
+		pods[idx] = corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name)}}
+
+		if node.unreachable {
+			errs[idx] = errors.New("connection refused")
+			continue
+		}
+
+		var ledger nodeLedger
+
+		ledger.Round.Index = node.round
+		ledger.Round.MerkleRoot = node.root
+		ledger.Peers = make([]struct {
+			Address string `json:"address"`
+		}, node.peers)
+		ledger.SyncStatus = node.sync
+
+		ledgers[idx] = ledger
+	}
+
+	return pods, ledgers, errs
+}
+
+func TestJudgeClusterHealth(t *testing.T) {
+	const (
+		healthy     = waveletv1alpha1.WaveletNodeHealthy
+		lagging     = waveletv1alpha1.WaveletNodeLagging
+		forked      = waveletv1alpha1.WaveletNodeForked
+		isolated    = waveletv1alpha1.WaveletNodeIsolated
+		outOfSync   = waveletv1alpha1.WaveletNodeOutOfSync
+		unreachable = waveletv1alpha1.WaveletNodeUnreachable
+	)
+
+	down := healthTestNode{unreachable: true}
+
+	tests := []struct {
+		name         string
+		size         int32
+		minPeers     int32
+		nodes        []healthTestNode
+		round        uint64
+		states       []waveletv1alpha1.WaveletNodeHealthState
+		agreeing     int
+		partitioning bool
+	}{
+		{
+			name:     "all healthy",
+			nodes:    []healthTestNode{reports(10, "a"), reports(10, "a"), reports(10, "a")},
+			round:    10,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, healthy, healthy},
+			agreeing: 3,
+		},
+		{
+			name:     "median round of an odd number of nodes",
+			nodes:    []healthTestNode{reports(12, ""), reports(10, ""), reports(11, ""), reports(30, ""), reports(10, "")},
+			round:    11,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, healthy, healthy, healthy, healthy},
+			agreeing: 5,
+		},
+		{
+			name:     "median round of an even number of nodes",
+			nodes:    []healthTestNode{reports(1, ""), reports(4, ""), reports(2, ""), reports(3, "")},
+			round:    3,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, healthy, healthy, healthy},
+			agreeing: 4,
+		},
+		{
+			name:     "median round ignores unreachable nodes",
+			nodes:    []healthTestNode{reports(20, ""), down, down, reports(30, "")},
+			round:    30,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, unreachable, unreachable, healthy},
+			agreeing: 2, partitioning: true,
+		},
+		{
+			name:     "lagging beyond the maximum round lag",
+			nodes:    []healthTestNode{reports(20, ""), reports(20, ""), reports(10, ""), reports(9, "")},
+			round:    20,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, healthy, healthy, lagging},
+			agreeing: 3,
+		},
+		{
+			name:     "ahead of the median round",
+			nodes:    []healthTestNode{reports(20, ""), reports(20, ""), reports(100, "")},
+			round:    20,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, healthy, healthy},
+			agreeing: 3,
+		},
+		{
+			name:     "forked from the majority merkle root",
+			nodes:    []healthTestNode{reports(10, "a"), reports(10, "b"), reports(10, "a")},
+			round:    10,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, forked, healthy},
+			agreeing: 2,
+		},
+		{
+			name:     "merkle roots of other rounds are not compared",
+			nodes:    []healthTestNode{reports(10, "a"), reports(10, "a"), reports(9, "z")},
+			round:    10,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, healthy, healthy},
+			agreeing: 3,
+		},
+		{
+			name:     "nodes without a merkle root are not compared",
+			nodes:    []healthTestNode{reports(10, "a"), reports(10, "a"), reports(10, "")},
+			round:    10,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, healthy, healthy},
+			agreeing: 3,
+		},
+		{
+			name:     "no majority merkle root forks no node",
+			nodes:    []healthTestNode{reports(10, "a"), reports(10, "a"), reports(10, "b"), reports(10, "b")},
+			round:    10,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, healthy, healthy, healthy},
+			agreeing: 4,
+		},
+		{
+			name:     "split brain with a minority fork",
+			nodes:    []healthTestNode{reports(10, "a"), reports(10, "b"), reports(10, "a"), reports(10, "b"), reports(10, "a")},
+			round:    10,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, forked, healthy, forked, healthy},
+			agreeing: 3,
+		},
+		{
+			name:     "split brain with half the nodes left behind",
+			nodes:    []healthTestNode{reports(20, ""), reports(5, ""), reports(20, ""), reports(5, "")},
+			round:    20,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, lagging, healthy, lagging},
+			agreeing: 2, partitioning: true,
+		},
+		{
+			name:     "unreachable",
+			nodes:    []healthTestNode{reports(10, ""), down, reports(10, "")},
+			round:    10,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, unreachable, healthy},
+			agreeing: 2,
+		},
+		{
+			name:     "all unreachable",
+			nodes:    []healthTestNode{down, down},
+			states:   []waveletv1alpha1.WaveletNodeHealthState{unreachable, unreachable},
+			agreeing: 0, partitioning: true,
+		},
+		{
+			name:     "exactly half healthy",
+			nodes:    []healthTestNode{reports(10, ""), down, reports(10, ""), down},
+			round:    10,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, unreachable, healthy, unreachable},
+			agreeing: 2, partitioning: true,
+		},
+		{
+			name:     "one more than half healthy",
+			nodes:    []healthTestNode{reports(10, ""), down, reports(10, ""), down, reports(10, "")},
+			round:    10,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, unreachable, healthy, unreachable, healthy},
+			agreeing: 3,
+		},
+		{
+			name:     "a single unhealthy node is not partitioned",
+			nodes:    []healthTestNode{down},
+			states:   []waveletv1alpha1.WaveletNodeHealthState{unreachable},
+			agreeing: 0,
+		},
+		{
+			name:     "isolated",
+			nodes:    []healthTestNode{reports(10, ""), {round: 10}, reports(10, "")},
+			round:    10,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, isolated, healthy},
+			agreeing: 2,
+		},
+		{
+			name:     "a lone node has no peers to connect to",
+			size:     1,
+			nodes:    []healthTestNode{{round: 10}},
+			round:    10,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy},
+			agreeing: 1,
+		},
+		{
+			name:     "fewer peers than configured",
+			minPeers: 3,
+			nodes:    []healthTestNode{reports(10, ""), {round: 10, peers: 3}, reports(10, ""), {round: 10, peers: 3}},
+			round:    10,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{isolated, healthy, isolated, healthy},
+			agreeing: 2, partitioning: true,
+		},
+		{
+			name:     "out of sync",
+			nodes:    []healthTestNode{reports(10, ""), {round: 10, peers: 2, sync: "Node is out of sync"}, {round: 10, peers: 2, sync: "Node is fully synced"}},
+			round:    10,
+			states:   []waveletv1alpha1.WaveletNodeHealthState{healthy, outOfSync, healthy},
+			agreeing: 2,
+		},
+	}
+
+	now := time.Now()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			size := test.size
+
+			if size == 0 {
+				size = int32(len(test.nodes))
+			}
+
+			cluster := newHealthTestCluster(size)
+			cluster.Spec.Health.MinPeers = test.minPeers
+
+			pods, ledgers, errs := newHealthTestInputs(test.nodes)
+
+			current := judgeClusterHealth(cluster, &clusterHealth{uid: cluster.UID, roundSince: now}, pods, ledgers, errs, false, now)
+
+			if current.round != test.round {
+				t.Errorf("expected round %d, got %d", test.round, current.round)
+			}
+
+			statuses := current.getNodeStatuses()
+
+			if len(statuses) != len(test.states) {
+				t.Fatalf("expected %d node statuses, got %d", len(test.states), len(statuses))
+			}
+
+			for i, status := range statuses {
+				if status.State != test.states[i] {
+					t.Errorf("expected node %d to be %s, got %s (%q)", i, test.states[i], status.State, status.Message)
+				}
+
+				if status.State != healthy && len(status.Message) == 0 {
+					t.Errorf("expected node %d to explain why it is %s", i, status.State)
+				}
+
+				if status.Stuck {
+					t.Errorf("expected node %d to not be stuck yet", i)
+				}
+			}
+
+			if current.agreeing != test.agreeing || current.checked != len(test.nodes) {
+				t.Errorf("expected %d/%d agreeing nodes, got %d/%d", test.agreeing, len(test.nodes), current.agreeing, current.checked)
+			}
+
+			if partitioning := !current.partitionedSince.IsZero(); partitioning != test.partitioning {
+				t.Errorf("expected partitioning to be %t, got %t", test.partitioning, partitioning)
+			}
+
+			if current.partitioned {
+				t.Errorf("expected the cluster to not be partitioned yet")
+			}
+		})
+	}
+}
+
+func TestJudgeClusterHealthPartitioned(t *testing.T) {
+	now := time.Now()
+	stuckAfter := DefaultHealthStuckSeconds * time.Second
+
+	split := []healthTestNode{reports(10, ""), {unreachable: true}}
+	whole := []healthTestNode{reports(10, ""), reports(10, "")}
+
+	tests := []struct {
+		name        string
+		nodes       []healthTestNode
+		since       time.Time
+		partitioned bool
+	}{
+		{name: "newly split", nodes: split, since: time.Time{}},
+		{name: "split for less than the stuck threshold", nodes: split, since: now.Add(-stuckAfter + time.Second)},
+		{name: "split for the stuck threshold", nodes: split, since: now.Add(-stuckAfter), partitioned: true},
+		{name: "healed", nodes: whole, since: now.Add(-stuckAfter)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cluster := newHealthTestCluster(int32(len(test.nodes)))
+			pods, ledgers, errs := newHealthTestInputs(test.nodes)
+
+			previous := &clusterHealth{uid: cluster.UID, roundSince: now, partitionedSince: test.since}
+			current := judgeClusterHealth(cluster, previous, pods, ledgers, errs, false, now)
+
+			if current.partitioned != test.partitioned {
+				t.Errorf("expected partitioned to be %t, got %t", test.partitioned, current.partitioned)
+			}
+
+			if test.partitioned && !current.partitionedSince.Equal(test.since) {
+				t.Errorf("expected the partition to be dated %s, got %s", test.since, current.partitionedSince)
+			}
+		})
+	}
+}
+
+func TestJudgeClusterHealthStuck(t *testing.T) {
+	now := time.Now()
+	stuckAfter := DefaultHealthStuckSeconds * time.Second
+
+	cluster := newHealthTestCluster(3)
+	pods, ledgers, errs := newHealthTestInputs([]healthTestNode{reports(20, ""), reports(20, ""), reports(5, "")})
+
+	previousStatus := func(state waveletv1alpha1.WaveletNodeHealthState, since time.Time) waveletv1alpha1.WaveletNodeHealthStatus {
+		return waveletv1alpha1.WaveletNodeHealthStatus{Node: "test-2", State: state, Since: metav1.NewTime(since)}
+	}
+
+	tests := []struct {
+		name     string
+		previous *nodeHealth
+		stuck    bool
+	}{
+		{name: "newly lagging"},
+		{name: "lagging for less than the stuck threshold", previous: &nodeHealth{uid: "test-2", status: previousStatus(waveletv1alpha1.WaveletNodeLagging, now.Add(-stuckAfter+time.Second))}},
+		{name: "lagging for the stuck threshold", previous: &nodeHealth{uid: "test-2", status: previousStatus(waveletv1alpha1.WaveletNodeLagging, now.Add(-stuckAfter))}, stuck: true},
+		{name: "previously in another state", previous: &nodeHealth{uid: "test-2", status: previousStatus(waveletv1alpha1.WaveletNodeUnreachable, now.Add(-stuckAfter))}},
+		{name: "pod recreated since", previous: &nodeHealth{uid: "recreated", status: previousStatus(waveletv1alpha1.WaveletNodeLagging, now.Add(-stuckAfter))}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			previous := &clusterHealth{uid: cluster.UID, roundSince: now, nodes: map[uint]nodeHealth{}}
+
+			if test.previous != nil {
+				previous.nodes[2] = *test.previous
+			}
+
+			current := judgeClusterHealth(cluster, previous, pods, ledgers, errs, false, now)
+
+			status := current.nodes[2].status
+
+			if status.State != waveletv1alpha1.WaveletNodeLagging {
+				t.Fatalf("expected the node to be lagging, got %s", status.State)
+			}
+
+			if status.Stuck != test.stuck {
+				t.Errorf("expected stuck to be %t, got %t", test.stuck, status.Stuck)
+			}
+
+			if stuck := current.getStuckNodes(); test.stuck && (len(stuck) != 1 || stuck[0] != "test-2") {
+				t.Errorf("expected only test-2 to be stuck, got %v", stuck)
+			}
+		})
+	}
+}
+
+func TestJudgeClusterHealthStalled(t *testing.T) {
+	now := time.Now()
+	stallAfter := DefaultHealthStallSeconds * time.Second
+
+	tests := []struct {
+		name    string
+		round   uint64
+		since   time.Time
+		loaded  bool
+		stalled bool
+
+		// reset is set should the time the round last advanced be reset to the time of the check.
+		reset bool
+	}{
+		{name: "idle", round: 10, since: now.Add(-stallAfter), reset: true},
+		{name: "loaded for less than the stall threshold", round: 10, since: now.Add(-stallAfter + time.Second), loaded: true},
+		{name: "loaded for the stall threshold", round: 10, since: now.Add(-stallAfter), loaded: true, stalled: true},
+		{name: "advanced while loaded", round: 9, since: now.Add(-stallAfter), loaded: true, reset: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cluster := newHealthTestCluster(2)
+			pods, ledgers, errs := newHealthTestInputs([]healthTestNode{reports(10, ""), reports(10, "")})
+
+			previous := &clusterHealth{uid: cluster.UID, round: test.round, roundSince: test.since}
+			current := judgeClusterHealth(cluster, previous, pods, ledgers, errs, test.loaded, now)
+
+			if current.stalled != test.stalled {
+				t.Errorf("expected stalled to be %t, got %t", test.stalled, current.stalled)
+			}
+
+			expected := test.since
+
+			if test.reset {
+				expected = now
+			}
+
+			if !current.roundSince.Equal(expected) {
+				t.Errorf("expected the round to be dated %s, got %s", expected, current.roundSince)
+			}
+		})
+	}
+}
+
+func TestIsNodeHealthy(t *testing.T) {
+	cluster := newHealthTestCluster(2)
+	cluster.Status.Health = []waveletv1alpha1.WaveletNodeHealthStatus{
+		{Node: "test-0", UID: "test-0", State: waveletv1alpha1.WaveletNodeHealthy},
+		{Node: "test-1", UID: "test-1", State: waveletv1alpha1.WaveletNodeLagging},
+	}
+
+	pod := func(name string, uid types.UID) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, UID: uid}}
+	}
+
+	tests := []struct {
+		name    string
+		pod     corev1.Pod
+		healthy bool
+	}{
+		{"healthy", pod("test-0", "test-0"), true},
+		{"lagging", pod("test-1", "test-1"), false},
+		{"replaced since last checked", pod("test-0", "replacement"), false},
+		{"never checked", pod("test-2", "test-2"), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if healthy := IsNodeHealthy(cluster, test.pod); healthy != test.healthy {
+				t.Errorf("expected healthy to be %t, got %t", test.healthy, healthy)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// DefaultProbeInitialDelaySeconds, DefaultProbePeriodSeconds and DefaultProbeFailureThreshold configure the probes
+// of nodes should a cluster leave them unset.
+const (
+	DefaultProbeInitialDelaySeconds  = 30
+	DefaultProbePeriodSeconds        = 10
+	DefaultProbeFailureThreshold     = 6
+	waveletProbeTimeoutSeconds       = 10
+	waveletReadinessFailureThreshold = 3
+)
+
+// WaveletLedgerPath is the path of the endpoint of the HTTP API of a node reporting the state of its ledger, its
+// sync status and its peers.
+const WaveletLedgerPath = "/ledger"
+
+// getWaveletProbes returns the readiness and liveness probes of the node container of a cluster. Both probe
+// WaveletLedgerPath: a node is restarted should its HTTP API stop responding, and is ready while it responds.
+//
+// The HTTP API of wavelet responds successfully to WaveletLedgerPath regardless of whether the node is in sync or
+// connected to any peers, and has no endpoint that does not. Those are instead judged by the health checker of the
+// operator from the body of its response, which is reflected in the health of each node in the status of a cluster.
+func getWaveletProbes(cluster *waveletv1alpha1.Wavelet) (readiness *corev1.Probe, liveness *corev1.Probe) {
+	probes := cluster.Spec.Probes
+
+	if probes.InitialDelaySeconds == 0 {
+		probes.InitialDelaySeconds = DefaultProbeInitialDelaySeconds
+	}
+
+	if probes.PeriodSeconds == 0 {
+		probes.PeriodSeconds = DefaultProbePeriodSeconds
+	}
+
+	if probes.FailureThreshold == 0 {
+		probes.FailureThreshold = DefaultProbeFailureThreshold
+	}
+
+	handler := corev1.Handler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path: WaveletLedgerPath,
+			Port: intstr.FromString("http"),
+		},
+	}
+
+	readiness = &corev1.Probe{
+		Handler:          handler,
+		TimeoutSeconds:   waveletProbeTimeoutSeconds,
+		PeriodSeconds:    probes.PeriodSeconds,
+		FailureThreshold: waveletReadinessFailureThreshold,
+	}
+
+	liveness = &corev1.Probe{
+		Handler:             handler,
+		InitialDelaySeconds: probes.InitialDelaySeconds,
+		TimeoutSeconds:      waveletProbeTimeoutSeconds,
+		PeriodSeconds:       probes.PeriodSeconds,
+		FailureThreshold:    probes.FailureThreshold,
+	}
+
+	return readiness, liveness
+}
@@ -0,0 +1,278 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+// DefaultRemediationMaxPerHour and DefaultRemediationBackoffSeconds limit how often stuck nodes are recreated should
+// a cluster leave them unset.
+const (
+	DefaultRemediationMaxPerHour     = 3
+	DefaultRemediationBackoffSeconds = 60
+)
+
+// remediationWindow is the window remediations are counted against the budget of a cluster within, and the maximum
+// backoff between remediations of the same node.
+const remediationWindow = time.Hour
+
+// getWaveletLedgerClaimName returns the name of the ledger volume claim of a node pod, which mirrors the name the
+// StatefulSet controller gives claims created from the volume claim templates of a StatefulSet.
+func getWaveletLedgerClaimName(pod corev1.Pod) string {
+	return "ledger-" + pod.Name
+}
+
+// isRemediable reports whether recreating a node stuck in a given state may help it recover. Nodes whose API is
+// unreachable are left to be restarted by their liveness probe.
+func isRemediable(state waveletv1alpha1.WaveletNodeHealthState) bool {
+	switch state {
+	case waveletv1alpha1.WaveletNodeLagging, waveletv1alpha1.WaveletNodeForked, waveletv1alpha1.WaveletNodeIsolated, waveletv1alpha1.WaveletNodeOutOfSync:
+		return true
+	default:
+		return false
+	}
+}
+
+// getWaveletRemediationBackoff returns the time a node must wait before being recreated, given the number of times
+// it was recreated within the last hour.
+func getWaveletRemediationBackoff(remediation *waveletv1alpha1.WaveletRemediationSpec, attempts int) time.Duration {
+	backoff := time.Duration(remediation.BackoffSeconds) * time.Second
+
+	if backoff == 0 {
+		backoff = DefaultRemediationBackoffSeconds * time.Second
+	}
+
+	for i := 1; i < attempts && backoff < remediationWindow; i++ {
+		backoff *= 2
+	}
+
+	if backoff > remediationWindow {
+		backoff = remediationWindow
+	}
+
+	return backoff
+}
+
+// pruneRemediations forgets remediations that happened longer than an hour ago.
+func pruneRemediations(status *waveletv1alpha1.WaveletStatus, now time.Time) {
+	var recent []waveletv1alpha1.WaveletRemediationStatus
+
+	for _, remediation := range status.Remediations {
+		if now.Sub(remediation.Time.Time) < remediationWindow {
+			recent = append(recent, remediation)
+		}
+	}
+
+	status.Remediations = recent
+}
+
+// recordRemediation appends a remediation to the status of a cluster and writes it. The cluster is read again and the
+// write retried should the cluster have changed since it was last read.
+func (r *ReconcileWavelet) recordRemediation(cluster *waveletv1alpha1.Wavelet, remediation waveletv1alpha1.WaveletRemediationStatus) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		remediations := cluster.Status.Remediations
+
+		cluster.Status.Remediations = append(remediations, remediation)
+
+		err := r.client.Status().Update(context.TODO(), cluster)
+
+		if err == nil {
+			return nil
+		}
+
+		cluster.Status.Remediations = remediations
+
+		if errors.IsConflict(err) {
+			if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}, cluster); err != nil {
+				return err
+			}
+		}
+
+		return err
+	})
+}
+
+// releaseWipedLedgers deletes node pods whose ledger volume claim is being deleted. Claims are protected from being
+// deleted for as long as a pod uses them, so the StatefulSet of a cluster may recreate a node before its wiped claim
+// is gone. Such nodes are deleted until their StatefulSet recreates them alongside a fresh claim.
+func (r *ReconcileWavelet) releaseWipedLedgers(logger logr.Logger, cluster *waveletv1alpha1.Wavelet, nodes map[uint]corev1.Pod) error {
+	if cluster.Spec.Storage == nil {
+		return nil
+	}
+
+	claims, err := r.listLedgerClaims(cluster)
+
+	if err != nil {
+		logger.Error(err, "Failed to list ledger volume claims.")
+		return err
+	}
+
+	wiped := make(map[string]struct{})
+
+	for _, claim := range claims {
+		if claim.GetDeletionTimestamp() != nil {
+			wiped[claim.Name] = struct{}{}
+		}
+	}
+
+	for _, pod := range nodes {
+		if _, exists := wiped[getWaveletLedgerClaimName(pod)]; !exists {
+			continue
+		}
+
+		if err := r.client.Delete(context.TODO(), &pod); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete node pod holding on to its wiped ledger.", "pod_name", pod.Name)
+			return err
+		}
+
+		logger.Info("Deleted node pod holding on to its wiped ledger.", "pod_name", pod.Name)
+	}
+
+	return nil
+}
+
+// remediateNodes recreates a node that has been stuck lagging behind, forked from or isolated from the rest of the
+// cluster, optionally wiping its ledger. At most one node is recreated at a time, nodes back off exponentially from
+// being recreated repeatedly, and no more nodes are recreated within an hour than the budget of the cluster allows.
+// Nodes are not recreated while the cluster is partitioned, as there is no majority for them to recover to. It
+// reports whether a node was recreated.
+func (r *ReconcileWavelet) remediateNodes(logger logr.Logger, cluster *waveletv1alpha1.Wavelet, nodes map[uint]corev1.Pod) (bool, error) {
+	remediation := cluster.Spec.Remediation
+
+	if remediation == nil {
+		return false, nil
+	}
+
+	health, checked := r.health.get(cluster)
+
+	if !checked || health.partitioned {
+		return false, nil
+	}
+
+	now := time.Now()
+
+	budget := int(remediation.MaxPerHour)
+
+	if budget == 0 {
+		budget = DefaultRemediationMaxPerHour
+	}
+
+	recent := 0
+	attempts := make(map[string][]time.Time)
+
+	for _, previous := range cluster.Status.Remediations {
+		if now.Sub(previous.Time.Time) < remediationWindow {
+			recent++
+			attempts[previous.Node] = append(attempts[previous.Node], previous.Time.Time)
+		}
+	}
+
+	ordinals := make([]uint, 0, len(health.nodes))
+
+	for idx := range health.nodes {
+		ordinals = append(ordinals, idx)
+	}
+
+	sort.Slice(ordinals, func(i, j int) bool { return ordinals[i] < ordinals[j] })
+
+	for _, idx := range ordinals {
+		node := health.nodes[idx]
+		pod, exists := nodes[idx]
+
+		// The health of a node is only acted upon should it describe the node pod as it exists today.
+		if !exists || pod.UID != node.uid || !node.status.Stuck || !isRemediable(node.status.State) {
+			continue
+		}
+
+		if recent >= budget {
+			logger.Info("Not recreating stuck node as the remediation budget of the cluster is exhausted.", "pod_name", pod.Name, "max_per_hour", budget)
+			return false, nil
+		}
+
+		if previous := attempts[pod.Name]; len(previous) > 0 {
+			if wait := previous[len(previous)-1].Add(getWaveletRemediationBackoff(remediation, len(previous))).Sub(now); wait > 0 {
+				logger.Info("Backing off from recreating stuck node.", "pod_name", pod.Name, "wait", wait.String())
+				continue
+			}
+		}
+
+		wipe := remediation.WipeLedger && cluster.Spec.Storage != nil
+
+		// The remediation is recorded before the node is recreated, such that it counts towards the budget of the
+		// cluster even should the rest of the reconciliation pass fail. A remediation that is recorded but fails to be
+		// carried out is backed off from like any other.
+		err := r.recordRemediation(cluster, waveletv1alpha1.WaveletRemediationStatus{
+			Node:        pod.Name,
+			Time:        metav1.NewTime(now.Truncate(time.Second)),
+			State:       node.status.State,
+			WipedLedger: wipe,
+			Message:     node.status.Message,
+		})
+
+		if err != nil {
+			logger.Error(err, "Failed to record the remediation of stuck node.", "pod_name", pod.Name)
+			return false, err
+		}
+
+		if wipe {
+			claim := new(corev1.PersistentVolumeClaim)
+
+			if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: getWaveletLedgerClaimName(pod)}, claim); err != nil && !errors.IsNotFound(err) {
+				logger.Error(err, "Failed to get the ledger volume claim of stuck node.", "pod_name", pod.Name)
+				return true, err
+			} else if err == nil {
+				if err := r.client.Delete(context.TODO(), claim); err != nil && !errors.IsNotFound(err) {
+					logger.Error(err, "Failed to wipe the ledger of stuck node.", "pod_name", pod.Name)
+					return true, err
+				}
+			}
+		}
+
+		if err := r.client.Delete(context.TODO(), &pod); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete stuck node pod.", "pod_name", pod.Name)
+			return true, err
+		}
+
+		wiped := ""
+
+		if wipe {
+			wiped = " and wiped its ledger"
+		}
+
+		r.recorder.Eventf(cluster, corev1.EventTypeWarning, "NodeRemediated", "Recreated node %s%s as it has been %s since %s: %s (%d/%d remediations within the last hour)", pod.Name, wiped, node.status.State, node.status.Since.UTC().Format(time.RFC3339), node.status.Message, recent+1, budget)
+
+		logger.Info("Recreated stuck node.", "pod_name", pod.Name, "state", node.status.State, "wiped_ledger", wipe)
+
+		return true, nil
+	}
+
+	return false, nil
+}
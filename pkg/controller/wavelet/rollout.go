@@ -0,0 +1,131 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// DefaultMaxUnavailable is the number of nodes that may be unavailable during a rollout should a cluster leave it
+// unset.
+const DefaultMaxUnavailable = 1
+
+func getWaveletMaxUnavailable(cluster *waveletv1alpha1.Wavelet) int {
+	if cluster.Spec.UpdateStrategy.MaxUnavailable < 1 {
+		return DefaultMaxUnavailable
+	}
+
+	return int(cluster.Spec.UpdateStrategy.MaxUnavailable)
+}
+
+// getWaveletStaleNodes returns the ordinals of all nodes whose pod spec drifted from the spec they would be rendered
+// with today, in the order they should be replaced.
+func getWaveletStaleNodes(cluster *waveletv1alpha1.Wavelet, nodes map[uint]corev1.Pod) []uint {
+	hash := getWaveletPodTemplate(cluster).Annotations[AnnotationSpecHash]
+
+	var stale []uint
+
+	for idx, pod := range nodes {
+		if pod.Annotations[AnnotationSpecHash] != hash {
+			stale = append(stale, idx)
+		}
+	}
+
+	bootstrapFirst := cluster.Spec.UpdateStrategy.BootstrapOrder == waveletv1alpha1.WaveletBootstrapFirst
+
+	// Replace nodes with the highest ordinal first, with the bootstrap node (ordinal 0) being replaced either before
+	// or after every other node.
+	sort.Slice(stale, func(i, j int) bool {
+		if bootstrapFirst && (stale[i] == 0 || stale[j] == 0) {
+			return stale[i] == 0
+		}
+
+		return stale[i] > stale[j]
+	})
+
+	return stale
+}
+
+// rolloutNodes replaces nodes whose pod spec drifted from the spec they would be rendered with today, such that no
+// more than the configured maximum number of nodes are ever unavailable at once. Nodes are available once they are
+// both ready and healthy (see IsNodeAvailable), such that each replacement is waited on until it rejoined the rest of
+// the cluster. Stale nodes that are unavailable already are replaced regardless, as replacing them takes nothing away
+// from the cluster. Nodes are only deleted by the operator; their StatefulSet recreates them with the latest pod
+// spec. It reports whether a rollout is in progress.
+func (r *ReconcileWavelet) rolloutNodes(logger logr.Logger, cluster *waveletv1alpha1.Wavelet, nodes map[uint]corev1.Pod) (bool, error) {
+	stale := getWaveletStaleNodes(cluster, nodes)
+
+	if len(stale) == 0 {
+		return false, nil
+	}
+
+	unavailable := 0
+
+	for idx := uint(0); idx < uint(cluster.Spec.Size); idx++ {
+		if pod, exists := nodes[idx]; !exists || !IsNodeAvailable(cluster, pod) {
+			unavailable++
+		}
+	}
+
+	budget := getWaveletMaxUnavailable(cluster) - unavailable
+	waiting := false
+
+	for _, idx := range stale {
+		// The bootstrap node is replaced on its own, after or before every other node has been replaced.
+		if idx == 0 && len(stale) > 1 && cluster.Spec.UpdateStrategy.BootstrapOrder != waveletv1alpha1.WaveletBootstrapFirst {
+			break
+		}
+
+		pod := nodes[idx]
+		available := IsNodeAvailable(cluster, pod)
+
+		if available && budget <= 0 {
+			waiting = true
+			continue
+		}
+
+		if err := r.client.Delete(context.TODO(), &pod); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete node pod for replacement.", "pod_name", pod.Name)
+			return true, err
+		}
+
+		logger.Info("Deleted node pod for replacement.", "pod_name", pod.Name, "num_stale_nodes", len(stale))
+
+		if available {
+			budget--
+		}
+
+		if idx == 0 {
+			break
+		}
+	}
+
+	if waiting {
+		logger.Info("Waiting for replaced nodes to become ready and healthy before continuing rollout...", "num_stale_nodes", len(stale), "num_unavailable_nodes", unavailable)
+	}
+
+	return true, nil
+}
@@ -20,20 +20,89 @@
 package wavelet
 
 import (
+	"encoding/json"
 	"fmt"
 	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
-	"io/ioutil"
+	"hash/fnv"
 	"k8s.io/apimachinery/pkg/labels"
 	"net"
+	"path/filepath"
 	"strconv"
+	"strings"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const ImageWavelet = "repo.treescale.com/perlin/wavelet"
 
-func labelsForWavelet(l ...string) labels.Set {
+// WalletMountPath is the directory in which the wallet secret of a cluster is mounted in node and benchmark pods.
+const WalletMountPath = "/etc/wavelet/wallets"
+
+// LedgerMountPath is the directory in which the ledger volume of a node is mounted should the cluster be configured
+// with persistent storage.
+const LedgerMountPath = "/var/lib/wavelet"
+
+// AnnotationSpecHash is set on objects rendered by the operator to a hash of their desired spec, such that the
+// operator is able to tell when an object has drifted from what it would render today.
+const AnnotationSpecHash = "wavelet.perlin.net/spec-hash"
+
+// AnnotationStorageHash is set on the StatefulSet of a cluster to a hash of its volume claim templates. Volume claim
+// templates are immutable, so a StatefulSet must be recreated should its storage hash change.
+const AnnotationStorageHash = "wavelet.perlin.net/storage-hash"
+
+// DefaultSnowballK and DefaultSnowballBeta are the Snowball parameters nodes are run with should a cluster leave them
+// unset.
+const (
+	DefaultSnowballK    = 10
+	DefaultSnowballBeta = 20
+)
+
+// waveletNodeScript starts a node within a StatefulSet pod. Every pod in the StatefulSet shares the same template,
+// so the wallet of a node is selected from the wallet secret mount based on the ordinal suffixed to its hostname (see
+// GetWaveletNodeWallet). The node with ordinal 0 is the bootstrap node, which is started with the wallet built into
+// the node image. Wallets of nodes added by a scale-up may take a while to be projected into the wallet secret mount,
+// so nodes wait for their wallet to appear rather than start with an unfunded identity, and exit should it not appear
+// in time such that their pod is restarted. Nodes bootstrap to every seed listed in $BOOTSTRAP_ADDRESSES other than
+// themselves, such that a restarted bootstrap node rejoins the cluster through the remaining seeds. Arguments passed
+// to the script are passed on to the node as flags.
+const waveletNodeScript = `ORDINAL="${HOSTNAME##*-}"
+
+SEEDS=""
+
+for SEED in $BOOTSTRAP_ADDRESSES; do
+	case "$SEED" in
+		"$HOSTNAME".*|"$HOSTNAME":*) ;;
+		*) SEEDS="$SEEDS $SEED" ;;
+	esac
+done
+
+if [ "$ORDINAL" = "0" ]; then
+	export WAVELET_WALLET="` + BootstrapWallet + `"
+	exec ./wavelet -api.port 9000 "$@" $SEEDS
+fi
+
+ATTEMPTS=0
+
+while [ "$ATTEMPTS" -lt 60 ]; do
+	for WALLET in "` + WalletMountPath + `/` + SecretKeyWalletPrefix + `$ORDINAL" "` + WalletMountPath + `/` + SecretKeyNodePrefix + `$ORDINAL"; do
+		if [ -f "$WALLET" ]; then
+			export WAVELET_WALLET="$WALLET"
+			exec ./wavelet -api.port 9000 "$@" $SEEDS
+		fi
+	done
+
+	ATTEMPTS=$((ATTEMPTS + 1))
+	sleep 2
+done
+
+echo "The wallet of node $ORDINAL did not appear in ` + WalletMountPath + `." >&2
+exit 1`
+
+// LabelsForWavelet returns the labels of resources of a given role belonging to a cluster, given the name of the
+// cluster followed by the role and optionally a class.
+func LabelsForWavelet(l ...string) labels.Set {
 	set := labels.Set{"app": l[0], "role": l[1]}
 
 	if len(l) == 3 {
@@ -43,120 +112,438 @@ func labelsForWavelet(l ...string) labels.Set {
 	return set
 }
 
-func getWaveletNodeWallet(pod corev1.Pod) string {
-	for _, env := range pod.Spec.Containers[0].Env {
-		if env.Name == "WAVELET_WALLET" {
-			return env.Value
-		}
+// getWaveletSize returns the number of nodes in a cluster.
+func getWaveletSize(cluster *waveletv1alpha1.Wavelet) uint {
+	if cluster.Spec.Size < 0 {
+		return 0
+	}
+
+	return uint(cluster.Spec.Size)
+}
+
+func getWaveletImage(cluster *waveletv1alpha1.Wavelet) string {
+	if len(cluster.Spec.Image) > 0 {
+		return cluster.Spec.Image
+	}
+
+	return ImageWavelet
+}
+
+func getWaveletBenchmarkImage(cluster *waveletv1alpha1.Wavelet) string {
+	if len(cluster.Spec.BenchmarkImage) > 0 {
+		return cluster.Spec.BenchmarkImage
+	}
+
+	return getWaveletImage(cluster)
+}
+
+func getWaveletImagePullSecrets(cluster *waveletv1alpha1.Wavelet) []corev1.LocalObjectReference {
+	if cluster.Spec.ImagePullSecrets != nil {
+		return cluster.Spec.ImagePullSecrets
+	}
+
+	return []corev1.LocalObjectReference{
+		{
+			Name: "regcred",
+		},
+	}
+}
+
+// HashObject returns a short hash of the JSON encoding of obj, which is used to tell when a rendered object has
+// drifted from its desired spec.
+func HashObject(obj interface{}) string {
+	buf, err := json.Marshal(obj)
+
+	if err != nil {
+		panic(err)
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write(buf)
+
+	return strconv.FormatUint(uint64(h.Sum32()), 16)
+}
+
+// GetWaveletPodOrdinal parses the index suffixed to the name of a pod whose name is of the form <prefix>-<idx>.
+func GetWaveletPodOrdinal(prefix string, pod corev1.Pod) (uint, bool) {
+	if !strings.HasPrefix(pod.Name, prefix+"-") {
+		return 0, false
 	}
 
-	panic("pod does not have a wallet available")
+	idx, err := strconv.ParseUint(pod.Name[len(prefix)+1:], 10, 32)
+
+	if err != nil {
+		return 0, false
+	}
+
+	return uint(idx), true
+}
+
+func getWaveletBootstrapPodName(cluster *waveletv1alpha1.Wavelet) string {
+	return fmt.Sprintf("%s-0", cluster.Name)
+}
+
+// getWaveletNumSeeds returns the number of seed nodes of a cluster, which are the nodes with the lowest ordinals.
+func getWaveletNumSeeds(cluster *waveletv1alpha1.Wavelet) uint {
+	if cluster.Spec.NumSeeds < 1 {
+		return 1
+	}
+
+	return uint(cluster.Spec.NumSeeds)
 }
 
-func getWaveletBenchmarkPod(cluster *waveletv1alpha1.Wavelet, pod corev1.Pod) *corev1.Pod {
-	idx, _ := strconv.ParseInt(pod.Name[len(cluster.Name):], 10, 32)
+// getWaveletSeedAddresses returns the addresses of all seed nodes of a cluster, which every node bootstraps to. The
+// addresses do not depend on the size of the cluster, such that scaling a cluster does not replace its nodes. The
+// webhook rejects clusters with more seeds than nodes, save for clusters scaled to 0 which run no nodes at all.
+func getWaveletSeedAddresses(cluster *waveletv1alpha1.Wavelet) []string {
+	seeds := make([]string, 0, getWaveletNumSeeds(cluster))
 
-	host := net.JoinHostPort(pod.Status.PodIP, "9000")
-	wallet := getWaveletNodeWallet(pod)
+	for idx := uint(0); idx < getWaveletNumSeeds(cluster); idx++ {
+		seeds = append(seeds, net.JoinHostPort(GetWaveletNodeHost(cluster, idx), "3000"))
+	}
+
+	return seeds
+}
+
+// GetWaveletNodeHost returns the stable DNS name of the node with a given ordinal, which is resolvable from within
+// the namespace of the cluster through the clusters headless service. It survives the node being rescheduled, and
+// is what the node advertises itself to its peers as.
+func GetWaveletNodeHost(cluster *waveletv1alpha1.Wavelet, idx uint) string {
+	return fmt.Sprintf("%s-%d.%s", cluster.Name, idx, cluster.Name)
+}
+
+// GetWaveletNodeWallet returns the path to the wallet the node with a given ordinal is started with. It mirrors the
+// wallet selection performed by waveletNodeScript, such that benchmark pods may use the same wallet as their node.
+// Nodes are started with the rich wallet matching their ordinal should there be one, or with a dedicated node wallet
+// otherwise. It reports false should the wallet of the node not have been generated yet, in which case the node waits
+// for it and callers are expected to do the same.
+func GetWaveletNodeWallet(secret *corev1.Secret, idx uint) (string, bool) {
+	if idx == 0 {
+		return BootstrapWallet, true
+	}
+
+	if key, exists := getWaveletNodeWalletKey(secret, idx); exists {
+		return filepath.Join(WalletMountPath, key), true
+	}
+
+	return "", false
+}
+
+// getWaveletBenchmarkPod returns the benchmark pod with a given ordinal, which targets the node with a given ordinal
+// using the wallet at a given path.
+func getWaveletBenchmarkPod(cluster *waveletv1alpha1.Wavelet, node uint, wallet string, idx uint) *corev1.Pod {
+	host := net.JoinHostPort(GetWaveletNodeHost(cluster, node), "9000")
+
+	spec := GetWaveletBenchmarkPodSpec(cluster, host, wallet)
 
 	return &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-benchmark-%d", cluster.Name, idx),
+			Name:        fmt.Sprintf("%s-benchmark-%d", cluster.Name, idx),
+			Namespace:   cluster.Namespace,
+			Labels:      LabelsForWavelet(cluster.Name, "benchmark"),
+			Annotations: map[string]string{AnnotationSpecHash: HashObject(spec)},
+		},
+		Spec: spec,
+	}
+}
+
+// getWaveletService returns the headless service governing the StatefulSet of a cluster, which gives each node a
+// stable DNS name (see GetWaveletNodeHost). Nodes are only ready once connected to their peers, so DNS names are
+// published regardless of whether nodes are ready such that peers are able to reach them in the first place. The
+// service is annotated with a hash of its spec, which is used to detect services needing to be updated.
+func getWaveletService(cluster *waveletv1alpha1.Wavelet) *corev1.Service {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cluster.Name,
 			Namespace: cluster.Namespace,
-			Labels:    labelsForWavelet(cluster.Name, "benchmark"),
+			Labels:    LabelsForWavelet(cluster.Name, "node"),
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:                corev1.ClusterIPNone,
+			PublishNotReadyAddresses: true,
+			Selector:                 LabelsForWavelet(cluster.Name, "node"),
+			Ports: []corev1.ServicePort{
+				{
+					Name: "node",
+					Port: 3000,
+				},
+				{
+					Name: "http",
+					Port: 9000,
+				},
+			},
 		},
-		Spec: getWaveletBenchmarkPodSpec(host, wallet),
 	}
+
+	service.Annotations = map[string]string{AnnotationSpecHash: HashObject(service.Spec)}
+
+	return service
 }
 
-func getWaveletBootstrapPod(cluster *waveletv1alpha1.Wavelet, genesis string) *corev1.Pod {
-	return &corev1.Pod{
+// getWaveletPodTemplate returns the template node pods of a cluster are created from. The template is annotated with
+// a hash of the pod spec it renders, which node pods inherit and which is used to detect nodes needing replacement.
+func getWaveletPodTemplate(cluster *waveletv1alpha1.Wavelet) corev1.PodTemplateSpec {
+	spec := getWaveletPodSpec(cluster, getWaveletSeedAddresses(cluster)...)
+
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      LabelsForWavelet(cluster.Name, "node"),
+			Annotations: map[string]string{AnnotationSpecHash: HashObject(spec)},
+		},
+		Spec: spec,
+	}
+}
+
+func getWaveletStatefulSet(cluster *waveletv1alpha1.Wavelet, secret *corev1.Secret) *appsv1.StatefulSet {
+	replicas := cluster.Spec.Size
+
+	if replicas < 0 {
+		replicas = 0
+	}
+
+	set := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      cluster.Name,
 			Namespace: cluster.Namespace,
-			Labels:    labelsForWavelet(cluster.Name, "node", "bootstrap"),
+			Labels:    LabelsForWavelet(cluster.Name, "node"),
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			ServiceName: cluster.Name,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: LabelsForWavelet(cluster.Name, "node"),
+			},
+			PodManagementPolicy: appsv1.OrderedReadyPodManagement,
+
+			// Nodes are replaced by the operator rather than by the StatefulSet controller. See rolloutNodes.
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: appsv1.OnDeleteStatefulSetStrategyType,
+			},
+			Template:             getWaveletPodTemplate(cluster),
+			VolumeClaimTemplates: getWaveletLedgerClaims(cluster),
 		},
-		Spec: getWaveletPodSpec("config/wallet.txt", genesis),
 	}
+
+	set.Annotations = map[string]string{
+		AnnotationSpecHash:    HashObject(set.Spec),
+		AnnotationStorageHash: HashObject(set.Spec.VolumeClaimTemplates),
+	}
+
+	return set
 }
 
-func getWaveletNodePod(cluster *waveletv1alpha1.Wavelet, genesis string, idx uint, bootstrap ...string) *corev1.Pod {
-	privateKey, err := ioutil.ReadFile(fmt.Sprintf("config/wallet%d.txt", idx))
+func getWaveletLedgerClaims(cluster *waveletv1alpha1.Wavelet) []corev1.PersistentVolumeClaim {
+	if cluster.Spec.Storage == nil {
+		return nil
+	}
 
-	if err != nil {
-		privateKey = []byte("random")
+	return []corev1.PersistentVolumeClaim{
+		{
+			// The StatefulSet controller labels claims with the selector of the StatefulSet, so claims of a cluster
+			// share the same labels as its node pods.
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "ledger",
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				StorageClassName: cluster.Spec.Storage.StorageClassName,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: cluster.Spec.Storage.Size,
+					},
+				},
+			},
+		},
 	}
+}
 
-	return &corev1.Pod{
+// GetWaveletWalletSecretName returns the name of the secret holding the genesis and wallets of a cluster.
+func GetWaveletWalletSecretName(cluster *waveletv1alpha1.Wavelet) string {
+	return fmt.Sprintf("%s-wallets", cluster.Name)
+}
+
+func getWaveletWalletSecret(cluster *waveletv1alpha1.Wavelet, genesis string, wallets map[string][]byte) *corev1.Secret {
+	data := map[string][]byte{SecretKeyGenesis: []byte(genesis)}
+
+	for key, privateKey := range wallets {
+		data[key] = privateKey
+	}
+
+	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-%d", cluster.Name, idx),
+			Name:      GetWaveletWalletSecretName(cluster),
 			Namespace: cluster.Namespace,
-			Labels:    labelsForWavelet(cluster.Name, "node"),
+			Labels:    LabelsForWavelet(cluster.Name, "wallets"),
 		},
-		Spec: getWaveletPodSpec(string(privateKey), genesis, bootstrap...),
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
 	}
 }
 
-func getWaveletBenchmarkPodSpec(host, wallet string) corev1.PodSpec {
+// getWaveletWalletVolume returns a read-only volume exposing every key in the wallet secret of a cluster as a file.
+// Private keys are only ever referenced by their path, such that they never appear in pod manifests.
+func getWaveletWalletVolume(secretName string) (corev1.Volume, corev1.VolumeMount) {
+	mode := int32(0400)
+
+	volume := corev1.Volume{
+		Name: "wallets",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  secretName,
+				DefaultMode: &mode,
+			},
+		},
+	}
+
+	mount := corev1.VolumeMount{
+		Name:      "wallets",
+		MountPath: WalletMountPath,
+		ReadOnly:  true,
+	}
+
+	return volume, mount
+}
+
+// GetWaveletBenchmarkPodSpec returns the spec of a pod that runs a benchmark client against the node API served at
+// host using the wallet at a given path.
+func GetWaveletBenchmarkPodSpec(cluster *waveletv1alpha1.Wavelet, host, wallet string) corev1.PodSpec {
+	volume, mount := getWaveletWalletVolume(GetWaveletWalletSecretName(cluster))
+
 	return corev1.PodSpec{
 		Containers: []corev1.Container{
 			{
-				Stdin:   true,
-				Image:   ImageWavelet,
-				Name:    "wavelet",
-				Command: []string{"./benchmark", "remote", "-host", host, "-wallet", wallet},
+				Stdin:           true,
+				Image:           getWaveletBenchmarkImage(cluster),
+				ImagePullPolicy: cluster.Spec.ImagePullPolicy,
+				Name:            "wavelet",
+				Command:         []string{"./benchmark", "remote", "-host", host, "-wallet", wallet},
+				VolumeMounts:    []corev1.VolumeMount{mount},
 			},
 		},
-		ImagePullSecrets: []corev1.LocalObjectReference{
-			{
-				Name: "regcred",
-			},
+		Volumes:          []corev1.Volume{volume},
+		ImagePullSecrets: getWaveletImagePullSecrets(cluster),
+	}
+}
+
+func getWaveletConsensusEnv(cluster *waveletv1alpha1.Wavelet) []corev1.EnvVar {
+	consensus := cluster.Spec.Consensus
+
+	if consensus.SnowballK == 0 {
+		consensus.SnowballK = DefaultSnowballK
+	}
+
+	if consensus.SnowballBeta == 0 {
+		consensus.SnowballBeta = DefaultSnowballBeta
+	}
+
+	env := []corev1.EnvVar{
+		{
+			Name:  "WAVELET_SNOWBALL_K",
+			Value: strconv.Itoa(int(consensus.SnowballK)),
+		},
+		{
+			Name:  "WAVELET_SNOWBALL_BETA",
+			Value: strconv.Itoa(int(consensus.SnowballBeta)),
+		},
+	}
+
+	if len(consensus.SnowballAlpha) > 0 {
+		env = append(env, corev1.EnvVar{
+			Name:  "WAVELET_SNOWBALL_ALPHA",
+			Value: consensus.SnowballAlpha,
+		})
+	}
+
+	return env
+}
+
+// getWaveletPuzzleEnv passes the puzzle difficulty of a cluster on to its nodes, such that nodes agree with the
+// operator on which keys are valid.
+func getWaveletPuzzleEnv(cluster *waveletv1alpha1.Wavelet) []corev1.EnvVar {
+	c1, c2 := getWaveletPuzzle(cluster)
+
+	return []corev1.EnvVar{
+		{
+			Name:  "WAVELET_SKADEMLIA_C1",
+			Value: strconv.Itoa(c1),
+		},
+		{
+			Name:  "WAVELET_SKADEMLIA_C2",
+			Value: strconv.Itoa(c2),
 		},
 	}
 }
 
-func getWaveletPodSpec(wallet string, genesis string, bootstrap ...string) corev1.PodSpec {
+func getWaveletPodSpec(cluster *waveletv1alpha1.Wavelet, bootstrap ...string) corev1.PodSpec {
+	secretName := GetWaveletWalletSecretName(cluster)
+
+	volume, mount := getWaveletWalletVolume(secretName)
+	mounts := []corev1.VolumeMount{mount}
+
+	dbPath := "db"
+
+	if cluster.Spec.Storage != nil {
+		dbPath = filepath.Join(LedgerMountPath, "db")
+
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      "ledger",
+			MountPath: LedgerMountPath,
+		})
+	}
+
+	memoryMax := cluster.Spec.MemoryMax
+
+	if memoryMax == 0 {
+		memoryMax = 4096
+	}
+
+	readiness, liveness := getWaveletProbes(cluster)
+
 	return corev1.PodSpec{
 		Containers: []corev1.Container{
 			{
-				Stdin:   true,
-				Image:   ImageWavelet,
-				Name:    "wavelet",
-				Command: append([]string{"./wavelet", "-api.port", strconv.Itoa(9000)}, bootstrap...),
-				Env: []corev1.EnvVar{
+				Stdin:           true,
+				Image:           getWaveletImage(cluster),
+				ImagePullPolicy: cluster.Spec.ImagePullPolicy,
+				Name:            "wavelet",
+				Command:         append([]string{"/bin/sh", "-c", waveletNodeScript, "wavelet"}, cluster.Spec.ExtraArgs...),
+				Env: append([]corev1.EnvVar{
 					{
-						Name: "WAVELET_NODE_HOST",
+						Name: "POD_NAME",
 						ValueFrom: &corev1.EnvVarSource{
 							FieldRef: &corev1.ObjectFieldSelector{
-								FieldPath: "status.podIP",
+								FieldPath: "metadata.name",
 							},
 						},
 					},
 					{
-						Name:  "WAVELET_SNOWBALL_K",
-						Value: "10",
+						// Nodes advertise their stable DNS name rather than their pod IP, such that peers are able
+						// to reach them again after they are rescheduled. See GetWaveletNodeHost.
+						Name:  "WAVELET_NODE_HOST",
+						Value: fmt.Sprintf("$(POD_NAME).%s", cluster.Name),
 					},
 					{
-						Name:  "WAVELET_SNOWBALL_BETA",
-						Value: "20",
+						Name:  "BOOTSTRAP_ADDRESSES",
+						Value: strings.Join(bootstrap, " "),
 					},
 					{
-						Name:  "WAVELET_GENESIS",
-						Value: genesis,
-					},
-					{
-						Name:  "WAVELET_WALLET",
-						Value: wallet,
+						Name: "WAVELET_GENESIS",
+						ValueFrom: &corev1.EnvVarSource{
+							SecretKeyRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+								Key:                  SecretKeyGenesis,
+							},
+						},
 					},
 					{
 						Name:  "WAVELET_DB_PATH",
-						Value: "db",
+						Value: dbPath,
 					},
 					{
-						Name: "WAVELET_MEMORY_MAX",
-						Value: "4096",
+						Name:  "WAVELET_MEMORY_MAX",
+						Value: strconv.Itoa(int(memoryMax)),
 					},
-				},
+				}, append(append(getWaveletConsensusEnv(cluster), getWaveletPuzzleEnv(cluster)...), cluster.Spec.ExtraEnv...)...),
 				Ports: []corev1.ContainerPort{
 					{
 						ContainerPort: 3000,
@@ -167,12 +554,12 @@ func getWaveletPodSpec(wallet string, genesis string, bootstrap ...string) corev
 						Name:          "http",
 					},
 				},
+				VolumeMounts:   mounts,
+				ReadinessProbe: readiness,
+				LivenessProbe:  liveness,
 			},
 		},
-		ImagePullSecrets: []corev1.LocalObjectReference{
-			{
-				Name: "regcred",
-			},
-		},
+		Volumes:          []corev1.Volume{volume},
+		ImagePullSecrets: getWaveletImagePullSecrets(cluster),
 	}
 }
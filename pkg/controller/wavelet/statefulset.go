@@ -0,0 +1,267 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"context"
+	"github.com/go-logr/logr"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ensureService creates the headless service governing the StatefulSet of a cluster, or updates it should the spec it
+// was last rendered with differ from the spec it would be rendered with today.
+func (r *ReconcileWavelet) ensureService(logger logr.Logger, cluster *waveletv1alpha1.Wavelet) error {
+	desired := getWaveletService(cluster)
+
+	if err := controllerutil.SetControllerReference(cluster, desired, r.scheme); err != nil {
+		return err
+	}
+
+	service := new(corev1.Service)
+
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, service); err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to query the headless service.")
+			return err
+		}
+
+		if err := r.client.Create(context.TODO(), desired); err != nil && !errors.IsAlreadyExists(err) {
+			logger.Error(err, "Failed to create headless service.")
+			return err
+		}
+
+		logger.Info("Created headless service.", "service_name", desired.Name)
+
+		return nil
+	}
+
+	if service.Annotations[AnnotationSpecHash] == desired.Annotations[AnnotationSpecHash] {
+		return nil
+	}
+
+	if service.Annotations == nil {
+		service.Annotations = make(map[string]string)
+	}
+
+	// The cluster IP of a service is immutable, and is left as is.
+	service.Annotations[AnnotationSpecHash] = desired.Annotations[AnnotationSpecHash]
+	service.Spec.PublishNotReadyAddresses = desired.Spec.PublishNotReadyAddresses
+	service.Spec.Selector = desired.Spec.Selector
+	service.Spec.Ports = desired.Spec.Ports
+
+	if err := r.client.Update(context.TODO(), service); err != nil {
+		logger.Error(err, "Failed to update headless service.")
+		return err
+	}
+
+	logger.Info("Updated headless service.", "service_name", service.Name)
+
+	return nil
+}
+
+// ensureStatefulSet creates the StatefulSet managing the node pods of a cluster, or updates it should the spec it
+// was last rendered with differ from the current spec of the cluster.
+func (r *ReconcileWavelet) ensureStatefulSet(logger logr.Logger, cluster *waveletv1alpha1.Wavelet, secret *corev1.Secret) error {
+	desired := getWaveletStatefulSet(cluster, secret)
+
+	if err := controllerutil.SetControllerReference(cluster, desired, r.scheme); err != nil {
+		return err
+	}
+
+	set := new(appsv1.StatefulSet)
+
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: desired.Namespace, Name: desired.Name}, set); err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to query the StatefulSet.")
+			return err
+		}
+
+		if err := r.client.Create(context.TODO(), desired); err != nil && !errors.IsAlreadyExists(err) {
+			logger.Error(err, "Failed to create the StatefulSet.")
+			return err
+		}
+
+		logger.Info("Created StatefulSet.", "statefulset_name", desired.Name, "replicas", *desired.Spec.Replicas)
+
+		return nil
+	}
+
+	if set.GetDeletionTimestamp() != nil {
+		logger.Info("Waiting for the StatefulSet to be deleted before recreating it...", "statefulset_name", set.Name)
+		return nil
+	}
+
+	storageHash, exists := set.Annotations[AnnotationStorageHash]
+
+	if !exists {
+		storageHash = HashObject(set.Spec.VolumeClaimTemplates)
+	}
+
+	if storageHash != desired.Annotations[AnnotationStorageHash] {
+		// Volume claim templates are immutable. Delete the StatefulSet while leaving its pods behind, such that the
+		// StatefulSet may be recreated and adopt them on the next pass.
+		if err := r.client.Delete(context.TODO(), set, client.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete the StatefulSet to apply changes to its storage.")
+			return err
+		}
+
+		logger.Info("Deleted StatefulSet to apply changes to its storage.", "statefulset_name", set.Name)
+
+		return nil
+	}
+
+	if set.Annotations[AnnotationSpecHash] == desired.Annotations[AnnotationSpecHash] {
+		return nil
+	}
+
+	if set.Annotations == nil {
+		set.Annotations = make(map[string]string)
+	}
+
+	set.Annotations[AnnotationSpecHash] = desired.Annotations[AnnotationSpecHash]
+	set.Annotations[AnnotationStorageHash] = desired.Annotations[AnnotationStorageHash]
+	set.Spec.Replicas = desired.Spec.Replicas
+	set.Spec.Template = desired.Spec.Template
+	set.Spec.UpdateStrategy = desired.Spec.UpdateStrategy
+
+	if err := r.client.Update(context.TODO(), set); err != nil {
+		logger.Error(err, "Failed to update the StatefulSet.")
+		return err
+	}
+
+	logger.Info("Updated StatefulSet.", "statefulset_name", set.Name, "replicas", *set.Spec.Replicas)
+
+	return nil
+}
+
+// ensureLedgerRetention applies the retention policy of a cluster to the ledger volumes of its nodes. Volumes are
+// garbage collected alongside the cluster by having the cluster own them should the policy be Delete.
+func (r *ReconcileWavelet) ensureLedgerRetention(logger logr.Logger, cluster *waveletv1alpha1.Wavelet) error {
+	claims, err := r.listLedgerClaims(cluster)
+
+	if err != nil {
+		logger.Error(err, "Failed to list ledger volume claims.")
+		return err
+	}
+
+	deleteWithCluster := cluster.Spec.Storage != nil && cluster.Spec.Storage.RetentionPolicy == waveletv1alpha1.WaveletStorageDelete
+
+	for _, claim := range claims {
+		owned := -1
+
+		for i, owner := range claim.OwnerReferences {
+			if owner.UID == cluster.UID {
+				owned = i
+				break
+			}
+		}
+
+		switch {
+		case deleteWithCluster && owned < 0:
+			claim.OwnerReferences = append(claim.OwnerReferences, metav1.OwnerReference{
+				APIVersion: waveletv1alpha1.SchemeGroupVersion.String(),
+				Kind:       "Wavelet",
+				Name:       cluster.Name,
+				UID:        cluster.UID,
+			})
+		case !deleteWithCluster && owned >= 0:
+			claim.OwnerReferences = append(claim.OwnerReferences[:owned], claim.OwnerReferences[owned+1:]...)
+		default:
+			continue
+		}
+
+		if err := r.client.Update(context.TODO(), &claim); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to apply retention policy to ledger volume claim.", "claim_name", claim.Name)
+			return err
+		}
+
+		logger.Info("Applied retention policy to ledger volume claim.", "claim_name", claim.Name, "delete_with_cluster", deleteWithCluster)
+	}
+
+	return nil
+}
+
+// labelSeedPods marks the node with ordinal 0 as the bootstrap node and all other seed nodes as seeds, such that
+// services and selectors may select them. Nodes that are no longer seeds have their mark removed.
+func (r *ReconcileWavelet) labelSeedPods(logger logr.Logger, cluster *waveletv1alpha1.Wavelet, nodes map[uint]corev1.Pod) error {
+	numSeeds := getWaveletNumSeeds(cluster)
+
+	for idx, pod := range nodes {
+		class := ""
+
+		switch {
+		case idx == 0:
+			class = "bootstrap"
+		case idx < numSeeds:
+			class = "seed"
+		}
+
+		if pod.Labels["class"] == class {
+			continue
+		}
+
+		if len(class) > 0 {
+			pod.Labels["class"] = class
+		} else {
+			delete(pod.Labels, "class")
+		}
+
+		if err := r.client.Update(context.TODO(), &pod); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to label the seed pod.", "pod_name", pod.Name, "class", class)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteLegacyNodePods deletes node pods that were created directly by earlier versions of the operator, rather than
+// through a StatefulSet. Their names would otherwise collide with the pods the StatefulSet of a cluster creates.
+func (r *ReconcileWavelet) deleteLegacyNodePods(logger logr.Logger, cluster *waveletv1alpha1.Wavelet) error {
+	nodePods, err := r.listPods(cluster, "node")
+
+	if err != nil {
+		logger.Error(err, "Failed to list all node pods created by the operator.")
+		return err
+	}
+
+	for _, pod := range nodePods {
+		if owner := metav1.GetControllerOf(&pod); owner == nil || owner.UID != cluster.UID {
+			continue
+		}
+
+		if err := r.client.Delete(context.TODO(), &pod); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete legacy node pod.", "pod_name", pod.Name)
+			return err
+		}
+
+		logger.Info("Deleted legacy node pod.", "pod_name", pod.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,261 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"context"
+	"fmt"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"reflect"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// IsPodReady reports whether the kubelet considers a pod to be ready.
+func IsPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// IsNodeHealthy reports whether the operator last judged a node pod of a cluster to be healthy, that is in sync,
+// connected to enough peers and keeping up with the rest of the cluster. Nodes that were not checked yet, including
+// pods that replaced a node since it was last checked, are not healthy.
+func IsNodeHealthy(cluster *waveletv1alpha1.Wavelet, pod corev1.Pod) bool {
+	return isNodeHealthy(cluster.Status.Health, pod)
+}
+
+func isNodeHealthy(health []waveletv1alpha1.WaveletNodeHealthStatus, pod corev1.Pod) bool {
+	for _, node := range health {
+		if node.Node == pod.Name && node.UID == pod.UID {
+			return node.State == waveletv1alpha1.WaveletNodeHealthy
+		}
+	}
+
+	return false
+}
+
+// IsNodeAvailable reports whether a node pod of a cluster is both ready, that is its HTTP API responds, and healthy.
+// Nodes that are ready may yet have to sync with or rejoin the rest of the cluster.
+func IsNodeAvailable(cluster *waveletv1alpha1.Wavelet, pod corev1.Pod) bool {
+	return IsPodReady(pod) && IsNodeHealthy(cluster, pod)
+}
+
+func setCondition(status *waveletv1alpha1.WaveletStatus, generation int64, typ waveletv1alpha1.WaveletConditionType, value corev1.ConditionStatus, reason, message string) {
+	condition := waveletv1alpha1.WaveletCondition{
+		Type:               typ,
+		Status:             value,
+		ObservedGeneration: generation,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+
+	for i, existing := range status.Conditions {
+		if existing.Type != typ {
+			continue
+		}
+
+		if existing.Status == value {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+
+		status.Conditions[i] = condition
+		return
+	}
+
+	status.Conditions = append(status.Conditions, condition)
+}
+
+func conditionStatus(b bool) corev1.ConditionStatus {
+	if b {
+		return corev1.ConditionTrue
+	}
+
+	return corev1.ConditionFalse
+}
+
+// updateStatus observes all pods belonging to a cluster, and writes a summary of them alongside the outcome of the
+// last reconciliation pass into the status subresource of the cluster.
+func (r *ReconcileWavelet) updateStatus(cluster *waveletv1alpha1.Wavelet, reconcileErr error) error {
+	nodePods, err := r.listPods(cluster, "node")
+
+	if err != nil {
+		return err
+	}
+
+	benchmarkPods, err := r.listPods(cluster, "benchmark")
+
+	if err != nil {
+		return err
+	}
+
+	status := cluster.Status.DeepCopy()
+
+	status.ObservedGeneration = cluster.Generation
+	status.Nodes = int32(len(nodePods))
+	status.ReadyNodes = 0
+	status.UpdatedNodes = 0
+	status.BenchmarkPods = int32(len(benchmarkPods))
+	status.BootstrapIP = ""
+	status.Seeds = getWaveletSeedAddresses(cluster)
+	status.WalletGeneration = r.wallets.progress(cluster)
+	status.Wallets = nil
+
+	secret := new(corev1.Secret)
+
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: GetWaveletWalletSecretName(cluster)}, secret); err == nil {
+		status.Wallets = getWaveletNodeWallets(cluster, secret, getWaveletSize(cluster))
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+
+	health, checked := r.health.get(cluster)
+
+	if checked {
+		status.Round, status.Health = health.round, health.getNodeStatuses()
+	}
+
+	var bootstrap *corev1.Pod
+	var failed []string
+
+	hash := getWaveletPodTemplate(cluster).Annotations[AnnotationSpecHash]
+
+	for i := range nodePods {
+		// Nodes respond to their readiness probe whether or not they are in sync with their peers, and are only
+		// counted as ready once judged healthy.
+		if IsPodReady(nodePods[i]) && isNodeHealthy(status.Health, nodePods[i]) {
+			status.ReadyNodes++
+		}
+
+		if nodePods[i].Annotations[AnnotationSpecHash] == hash {
+			status.UpdatedNodes++
+		}
+
+		if nodePods[i].Name == getWaveletBootstrapPodName(cluster) {
+			bootstrap = &nodePods[i]
+			status.BootstrapIP = bootstrap.Status.PodIP
+		}
+
+		if nodePods[i].Status.Phase == corev1.PodFailed {
+			failed = append(failed, nodePods[i].Name)
+		}
+	}
+
+	for _, pod := range benchmarkPods {
+		if pod.Status.Phase == corev1.PodFailed {
+			failed = append(failed, pod.Name)
+		}
+	}
+
+	expectedNumNodes := cluster.Spec.Size
+
+	if expectedNumNodes < 0 {
+		expectedNumNodes = 0
+	}
+
+	nodes := make(map[uint]corev1.Pod, len(nodePods))
+
+	for _, pod := range nodePods {
+		if idx, ok := GetWaveletPodOrdinal(cluster.Name, pod); ok {
+			nodes[idx] = pod
+		}
+	}
+
+	// Benchmark pods are only created for clients that could be assigned a node to target.
+	targets, _ := GetWaveletBenchmarkTargets(cluster.Spec.BenchmarkTarget, cluster.Name, cluster.Spec.NumBenchmarkPods, uint(expectedNumNodes), nodes)
+
+	expectedNumBenchmarkPods := int32(len(targets))
+
+	pruneRemediations(status, time.Now())
+
+	_, _, _, stallAfter := getWaveletHealthThresholds(cluster)
+
+	var reason, message string
+
+	switch {
+	case reconcileErr != nil:
+		status.Phase = waveletv1alpha1.WaveletPhaseDegraded
+		reason, message = "ReconcileFailed", reconcileErr.Error()
+	case cluster.GetDeletionTimestamp() != nil:
+		status.Phase = waveletv1alpha1.WaveletPhaseTerminating
+		reason, message = "Terminating", "The cluster is being archived and torn down."
+	case len(failed) > 0:
+		status.Phase = waveletv1alpha1.WaveletPhaseDegraded
+		reason, message = "PodFailed", fmt.Sprintf("Pods %v have failed.", failed)
+	case expectedNumNodes > 0 && cluster.Spec.NumBenchmarkPods > 0 && expectedNumBenchmarkPods == 0:
+		status.Phase = waveletv1alpha1.WaveletPhaseDegraded
+		reason, message = "NoBenchmarkTargets", fmt.Sprintf("None of the %d nodes match the benchmark target of the cluster.", expectedNumNodes)
+	case status.WalletGeneration != nil:
+		status.Phase = waveletv1alpha1.WaveletPhaseBootstrapping
+		reason, message = "GeneratingWallets", fmt.Sprintf("%d/%d wallets are generated.", status.WalletGeneration.Generated, status.WalletGeneration.Total)
+	case expectedNumNodes > 0 && (bootstrap == nil || !IsPodReady(*bootstrap)):
+		status.Phase = waveletv1alpha1.WaveletPhaseBootstrapping
+		reason, message = "BootstrapNotReady", "Waiting for the bootstrap node to be ready."
+	case health.partitioned:
+		status.Phase = waveletv1alpha1.WaveletPhaseDegraded
+		reason, message = "Partitioned", fmt.Sprintf("Only %d/%d nodes agree on round %d.", health.agreeing, health.checked, health.round)
+	case health.stalled:
+		status.Phase = waveletv1alpha1.WaveletPhaseDegraded
+		reason, message = "Stalled", fmt.Sprintf("Round %d has not advanced for over %s while benchmarks are running.", health.round, stallAfter)
+	case len(health.getStuckNodes()) > 0:
+		status.Phase = waveletv1alpha1.WaveletPhaseDegraded
+		reason, message = "NodesStuck", fmt.Sprintf("Nodes %v are stuck.", health.getStuckNodes())
+	case status.UpdatedNodes != status.Nodes:
+		status.Phase = waveletv1alpha1.WaveletPhaseScaling
+		reason, message = "RollingUpdate", fmt.Sprintf("%d/%d nodes are updated.", status.UpdatedNodes, status.Nodes)
+	case status.Nodes != expectedNumNodes || status.ReadyNodes != expectedNumNodes:
+		status.Phase = waveletv1alpha1.WaveletPhaseScaling
+		reason, message = "NodesNotReady", fmt.Sprintf("%d/%d nodes are ready and healthy.", status.ReadyNodes, expectedNumNodes)
+	case status.BenchmarkPods != expectedNumBenchmarkPods:
+		status.Phase = waveletv1alpha1.WaveletPhaseScaling
+		reason, message = "BenchmarkPodsNotReady", fmt.Sprintf("%d/%d benchmark pods are created.", status.BenchmarkPods, expectedNumBenchmarkPods)
+	case status.BenchmarkPods > 0:
+		status.Phase = waveletv1alpha1.WaveletPhaseBenchmarking
+		reason, message = "Benchmarking", fmt.Sprintf("%d benchmark pods are running against %d nodes.", status.BenchmarkPods, status.ReadyNodes)
+	default:
+		status.Phase = waveletv1alpha1.WaveletPhaseReady
+		reason, message = "NodesReady", fmt.Sprintf("%d/%d nodes are ready.", status.ReadyNodes, expectedNumNodes)
+	}
+
+	degraded := status.Phase == waveletv1alpha1.WaveletPhaseDegraded
+	progressing := status.Phase == waveletv1alpha1.WaveletPhaseBootstrapping || status.Phase == waveletv1alpha1.WaveletPhaseScaling || status.Phase == waveletv1alpha1.WaveletPhaseTerminating
+	ready := status.Phase == waveletv1alpha1.WaveletPhaseReady || status.Phase == waveletv1alpha1.WaveletPhaseBenchmarking
+
+	setCondition(status, cluster.Generation, waveletv1alpha1.WaveletConditionReady, conditionStatus(ready), reason, message)
+	setCondition(status, cluster.Generation, waveletv1alpha1.WaveletConditionProgressing, conditionStatus(progressing), reason, message)
+	setCondition(status, cluster.Generation, waveletv1alpha1.WaveletConditionDegraded, conditionStatus(degraded), reason, message)
+
+	if reflect.DeepEqual(status, &cluster.Status) {
+		return nil
+	}
+
+	cluster.Status = *status
+
+	return r.client.Status().Update(context.TODO(), cluster)
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"fmt"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"hash/fnv"
+	"k8s.io/apimachinery/pkg/labels"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getBenchmarkTargetStrategy(target *waveletv1alpha1.WaveletBenchmarkTargetSpec) waveletv1alpha1.WaveletBenchmarkTargetStrategy {
+	if target == nil || len(target.Strategy) == 0 {
+		return waveletv1alpha1.WaveletBenchmarkTargetRoundRobin
+	}
+
+	return target.Strategy
+}
+
+// GetWaveletBenchmarkTargets assigns each of a number of benchmark clients the ordinal of the node it targets in a
+// cluster of a given size. seed keeps the nodes picked by the Random strategy stable across reconciliation passes.
+// Node pods keyed by their ordinal are only consulted by the Selector strategy, and no targets are returned should
+// none of them match.
+func GetWaveletBenchmarkTargets(target *waveletv1alpha1.WaveletBenchmarkTargetSpec, seed string, clients, size uint, nodes map[uint]corev1.Pod) ([]uint, error) {
+	if clients == 0 || size == 0 {
+		return nil, nil
+	}
+
+	targets := make([]uint, clients)
+
+	switch getBenchmarkTargetStrategy(target) {
+	case waveletv1alpha1.WaveletBenchmarkTargetBootstrap:
+		// Every client targets the node with ordinal 0.
+	case waveletv1alpha1.WaveletBenchmarkTargetRandom:
+		for i := range targets {
+			h := fnv.New32a()
+			_, _ = fmt.Fprintf(h, "%s/%d", seed, i)
+
+			targets[i] = uint(h.Sum32()) % size
+		}
+	case waveletv1alpha1.WaveletBenchmarkTargetSelector:
+		selector, err := metav1.LabelSelectorAsSelector(target.Selector)
+
+		if err != nil {
+			return nil, err
+		}
+
+		var candidates []uint
+
+		for idx, pod := range nodes {
+			if idx < size && selector.Matches(labels.Set(pod.Labels)) {
+				candidates = append(candidates, idx)
+			}
+		}
+
+		if len(candidates) == 0 {
+			return nil, nil
+		}
+
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i] < candidates[j] })
+
+		for i := range targets {
+			targets[i] = candidates[uint(i)%uint(len(candidates))]
+		}
+	default:
+		for i := range targets {
+			targets[i] = uint(i) % size
+		}
+	}
+
+	return targets, nil
+}
+
+// GetWaveletBenchmarkWallet returns the path to the wallet the benchmark client with a given ordinal signs
+// transactions with, given the ordinal of the node it targets in a cluster of a given size. It reports false should the
+// wallet not have been generated yet. In Split mode, clients whose ordinal is a multiple of the size of the cluster
+// (client 0 included) map to the node with ordinal 0, and thus use the bootstrap wallet built into the node image.
+func GetWaveletBenchmarkWallet(target *waveletv1alpha1.WaveletBenchmarkTargetSpec, secret *corev1.Secret, client, node, size uint) (string, bool) {
+	return GetWaveletNodeWallet(secret, GetWaveletBenchmarkWalletNode(target, client, node, size))
+}
+
+// GetWaveletBenchmarkWalletNode returns the ordinal of the node whose wallet the benchmark client with a given ordinal
+// signs transactions with, given the ordinal of the node it targets in a cluster of a given size.
+func GetWaveletBenchmarkWalletNode(target *waveletv1alpha1.WaveletBenchmarkTargetSpec, client, node, size uint) uint {
+	if target != nil && target.Wallets == waveletv1alpha1.WaveletBenchmarkWalletsSplit && size > 0 {
+		return client % size
+	}
+
+	return node
+}
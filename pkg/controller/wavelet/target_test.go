@@ -0,0 +1,191 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"fmt"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newTargetTestNodes returns the pods of nodes keyed by their ordinal, labelled with a zone each.
+func newTargetTestNodes(zones ...string) map[uint]corev1.Pod {
+	nodes := make(map[uint]corev1.Pod, len(zones))
+
+	for idx, zone := range zones {
+		nodes[uint(idx)] = corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("test-%d", idx), Labels: map[string]string{"zone": zone}}}
+	}
+
+	return nodes
+}
+
+func TestGetWaveletBenchmarkTargets(t *testing.T) {
+	strategy := func(strategy waveletv1alpha1.WaveletBenchmarkTargetStrategy) *waveletv1alpha1.WaveletBenchmarkTargetSpec {
+		return &waveletv1alpha1.WaveletBenchmarkTargetSpec{Strategy: strategy}
+	}
+
+	zone := func(zone string) *waveletv1alpha1.WaveletBenchmarkTargetSpec {
+		return &waveletv1alpha1.WaveletBenchmarkTargetSpec{
+			Strategy: waveletv1alpha1.WaveletBenchmarkTargetSelector,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"zone": zone}},
+		}
+	}
+
+	nodes := newTargetTestNodes("a", "b", "a", "b", "a")
+
+	tests := []struct {
+		name     string
+		target   *waveletv1alpha1.WaveletBenchmarkTargetSpec
+		clients  uint
+		size     uint
+		nodes    map[uint]corev1.Pod
+		expected []uint
+	}{
+		{name: "round robin by default", target: nil, clients: 3, size: 3, expected: []uint{0, 1, 2}},
+		{name: "round robin without strategy", target: &waveletv1alpha1.WaveletBenchmarkTargetSpec{}, clients: 2, size: 3, expected: []uint{0, 1}},
+		{name: "round robin over more clients than nodes", target: strategy(waveletv1alpha1.WaveletBenchmarkTargetRoundRobin), clients: 7, size: 3, expected: []uint{0, 1, 2, 0, 1, 2, 0}},
+		{name: "bootstrap", target: strategy(waveletv1alpha1.WaveletBenchmarkTargetBootstrap), clients: 4, size: 3, expected: []uint{0, 0, 0, 0}},
+		{name: "selector", target: zone("b"), clients: 5, size: 5, nodes: nodes, expected: []uint{1, 3, 1, 3, 1}},
+		{name: "selector ignores nodes beyond the size", target: zone("a"), clients: 4, size: 3, nodes: nodes, expected: []uint{0, 2, 0, 2}},
+		{name: "selector matching no nodes", target: zone("c"), clients: 4, size: 5, nodes: nodes, expected: nil},
+		{name: "selector without nodes", target: zone("a"), clients: 4, size: 5, nodes: nil, expected: nil},
+		{name: "no clients", target: nil, clients: 0, size: 3, expected: nil},
+		{name: "no nodes", target: strategy(waveletv1alpha1.WaveletBenchmarkTargetRandom), clients: 3, size: 0, expected: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := GetWaveletBenchmarkTargets(test.target, "seed", test.clients, test.size, test.nodes)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGetWaveletBenchmarkTargetsRandom(t *testing.T) {
+	target := &waveletv1alpha1.WaveletBenchmarkTargetSpec{Strategy: waveletv1alpha1.WaveletBenchmarkTargetRandom}
+
+	const clients, size = 64, 8
+
+	first, err := GetWaveletBenchmarkTargets(target, "seed", clients, size, nil)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(first) != clients {
+		t.Fatalf("expected %d targets, got %d", clients, len(first))
+	}
+
+	picked := make(map[uint]struct{})
+
+	for _, node := range first {
+		if node >= size {
+			t.Fatalf("expected targets within [0, %d), got %v", size, first)
+		}
+
+		picked[node] = struct{}{}
+	}
+
+	if len(picked) < 2 {
+		t.Errorf("expected targets to be spread across nodes, got %v", first)
+	}
+
+	second, _ := GetWaveletBenchmarkTargets(target, "seed", clients, size, nil)
+
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("expected targets to be stable for the same seed, got %v and %v", first, second)
+	}
+
+	// Adding clients leaves the targets of existing clients as they are.
+	more, _ := GetWaveletBenchmarkTargets(target, "seed", clients+1, size, nil)
+
+	if !reflect.DeepEqual(first, more[:clients]) {
+		t.Errorf("expected targets of existing clients to be kept, got %v and %v", first, more[:clients])
+	}
+
+	other, _ := GetWaveletBenchmarkTargets(target, "other", clients, size, nil)
+
+	if reflect.DeepEqual(first, other) {
+		t.Errorf("expected targets to differ across seeds, got %v for both", first)
+	}
+}
+
+func TestGetWaveletBenchmarkTargetsInvalidSelector(t *testing.T) {
+	target := &waveletv1alpha1.WaveletBenchmarkTargetSpec{
+		Strategy: waveletv1alpha1.WaveletBenchmarkTargetSelector,
+		Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "zone", Operator: "Near"}}},
+	}
+
+	if _, err := GetWaveletBenchmarkTargets(target, "seed", 1, 1, newTargetTestNodes("a")); err == nil {
+		t.Errorf("expected an invalid selector to be rejected")
+	}
+}
+
+func TestGetWaveletBenchmarkWallet(t *testing.T) {
+	// Node 1 is assigned a rich wallet, node 2 a node wallet, and the wallet of node 3 is yet to be generated.
+	secret := &corev1.Secret{Data: map[string][]byte{walletSecretKey(1): nil, nodeSecretKey(2): nil}}
+
+	shared := &waveletv1alpha1.WaveletBenchmarkTargetSpec{Wallets: waveletv1alpha1.WaveletBenchmarkWalletsShared}
+	split := &waveletv1alpha1.WaveletBenchmarkTargetSpec{Wallets: waveletv1alpha1.WaveletBenchmarkWalletsSplit}
+
+	tests := []struct {
+		name     string
+		target   *waveletv1alpha1.WaveletBenchmarkTargetSpec
+		client   uint
+		node     uint
+		size     uint
+		expected string
+		exists   bool
+	}{
+		{name: "shared by default", target: nil, client: 2, node: 1, size: 4, expected: filepath.Join(WalletMountPath, walletSecretKey(1)), exists: true},
+		{name: "shared rich wallet", target: shared, client: 0, node: 1, size: 4, expected: filepath.Join(WalletMountPath, walletSecretKey(1)), exists: true},
+		{name: "shared node wallet", target: shared, client: 0, node: 2, size: 4, expected: filepath.Join(WalletMountPath, nodeSecretKey(2)), exists: true},
+		{name: "shared bootstrap wallet", target: shared, client: 1, node: 0, size: 4, expected: BootstrapWallet, exists: true},
+		{name: "shared wallet not generated yet", target: shared, client: 0, node: 3, size: 4},
+		{name: "split by client", target: split, client: 2, node: 1, size: 4, expected: filepath.Join(WalletMountPath, nodeSecretKey(2)), exists: true},
+		{name: "split client 0 uses the bootstrap wallet", target: split, client: 0, node: 2, size: 4, expected: BootstrapWallet, exists: true},
+		{name: "split wraps around the size", target: split, client: 5, node: 0, size: 4, expected: filepath.Join(WalletMountPath, walletSecretKey(1)), exists: true},
+		{name: "split multiple of the size uses the bootstrap wallet", target: split, client: 8, node: 1, size: 4, expected: BootstrapWallet, exists: true},
+		{name: "split wallet not generated yet", target: split, client: 3, node: 0, size: 4},
+		{name: "split without nodes falls back to the node", target: split, client: 3, node: 1, size: 0, expected: filepath.Join(WalletMountPath, walletSecretKey(1)), exists: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, exists := GetWaveletBenchmarkWallet(test.target, secret, test.client, test.node, test.size)
+
+			if exists != test.exists || actual != test.expected {
+				t.Errorf("expected (%q, %t), got (%q, %t)", test.expected, test.exists, actual, exists)
+			}
+		})
+	}
+}
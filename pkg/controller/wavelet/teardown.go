@@ -0,0 +1,307 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-logr/logr"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// FinalizerTeardown is set on every cluster such that it is archived and torn down in order by the operator before
+// it is released.
+const FinalizerTeardown = "wavelet.perlin.net/teardown"
+
+func hasFinalizer(cluster *waveletv1alpha1.Wavelet) bool {
+	for _, finalizer := range cluster.GetFinalizers() {
+		if finalizer == FinalizerTeardown {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ensureFinalizer sets the teardown finalizer on a cluster should it not already be set.
+func (r *ReconcileWavelet) ensureFinalizer(logger logr.Logger, cluster *waveletv1alpha1.Wavelet) error {
+	if hasFinalizer(cluster) {
+		return nil
+	}
+
+	cluster.SetFinalizers(append(cluster.GetFinalizers(), FinalizerTeardown))
+
+	if err := r.client.Update(context.TODO(), cluster); err != nil {
+		logger.Error(err, "Failed to set the teardown finalizer on the cluster.")
+		return err
+	}
+
+	return nil
+}
+
+// teardown archives and tears down a cluster that is being deleted, and releases it once done. Benchmark pods are
+// deleted first, followed by all nodes. Once all nodes have stopped, the wallet secret and ledger volumes of the
+// cluster are archived one at a time should an archive be configured. The StatefulSet, Service, ledger volumes (per
+// the retention policy of the cluster) and wallet secret are deleted last, in that order.
+//
+// teardown reports whether the cluster was released, in which case it no longer exists.
+func (r *ReconcileWavelet) teardown(logger logr.Logger, cluster *waveletv1alpha1.Wavelet) (reconcile.Result, bool, error) {
+	r.wallets.cancel(types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name})
+
+	if !hasFinalizer(cluster) {
+		return reconcile.Result{}, true, nil
+	}
+
+	benchmarkPods, err := r.listPods(cluster, "benchmark")
+
+	if err != nil {
+		logger.Error(err, "Failed to list all benchmark pods created by the operator.")
+		return reconcile.Result{}, false, err
+	}
+
+	for _, benchmarkPod := range benchmarkPods {
+		if err := r.client.Delete(context.TODO(), &benchmarkPod); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete benchmark pod.", "pod_name", benchmarkPod.Name)
+			return reconcile.Result{}, false, err
+		}
+
+		logger.Info("Deleted benchmark pod.", "pod_name", benchmarkPod.Name)
+	}
+
+	if stopped, err := r.stopNodes(logger, cluster); err != nil || !stopped {
+		return reconcile.Result{RequeueAfter: 2 * time.Second}, false, err
+	}
+
+	if cluster.Spec.Archive != nil {
+		archived, err := r.archive(logger, cluster)
+
+		if err != nil || !archived {
+			return reconcile.Result{RequeueAfter: 5 * time.Second}, false, err
+		}
+
+		r.recorder.Eventf(cluster, corev1.EventTypeNormal, "Archived", "Archived the cluster to %s.", getWaveletArchiveLocation(cluster))
+	}
+
+	claims, err := r.listLedgerClaims(cluster)
+
+	if err != nil {
+		logger.Error(err, "Failed to list ledger volume claims.")
+		return reconcile.Result{}, false, err
+	}
+
+	deleteClaims := cluster.Spec.Storage != nil && cluster.Spec.Storage.RetentionPolicy == waveletv1alpha1.WaveletStorageDelete
+
+	objects := []runtime.Object{
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: cluster.Name}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: cluster.Name}},
+	}
+
+	if deleteClaims {
+		for i := range claims {
+			objects = append(objects, &claims[i])
+		}
+	}
+
+	objects = append(objects, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: cluster.Namespace, Name: GetWaveletWalletSecretName(cluster)}})
+
+	for _, obj := range objects {
+		if err := r.client.Delete(context.TODO(), obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to tear down an object of the cluster.", "kind", fmt.Sprintf("%T", obj))
+			return reconcile.Result{}, false, err
+		}
+	}
+
+	logger.Info("Tore down the cluster.", "deleted_ledger_volumes", deleteClaims)
+
+	var finalizers []string
+
+	for _, finalizer := range cluster.GetFinalizers() {
+		if finalizer != FinalizerTeardown {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+
+	cluster.SetFinalizers(finalizers)
+
+	if err := r.client.Update(context.TODO(), cluster); err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Failed to remove the teardown finalizer from the cluster.")
+		return reconcile.Result{}, false, err
+	}
+
+	return reconcile.Result{}, true, nil
+}
+
+// stopNodes scales the StatefulSet of a cluster down to zero, and reports whether all of its node pods are gone such
+// that their ledger volumes may be mounted elsewhere.
+func (r *ReconcileWavelet) stopNodes(logger logr.Logger, cluster *waveletv1alpha1.Wavelet) (bool, error) {
+	set := new(appsv1.StatefulSet)
+
+	err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}, set)
+
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Failed to query the StatefulSet.")
+		return false, err
+	}
+
+	if err == nil && (set.Spec.Replicas == nil || *set.Spec.Replicas != 0) {
+		replicas := int32(0)
+		set.Spec.Replicas = &replicas
+
+		if err := r.client.Update(context.TODO(), set); err != nil {
+			logger.Error(err, "Failed to scale the StatefulSet down.")
+			return false, err
+		}
+
+		logger.Info("Scaled the StatefulSet down to stop all nodes.", "statefulset_name", set.Name)
+	}
+
+	list := new(corev1.PodList)
+
+	opts := &client.ListOptions{Namespace: cluster.Namespace, LabelSelector: labels.SelectorFromSet(LabelsForWavelet(cluster.Name, "node"))}
+
+	if err := r.client.List(context.TODO(), opts, list); err != nil {
+		logger.Error(err, "Failed to list all node pods created by the operator.")
+		return false, err
+	}
+
+	if len(list.Items) > 0 {
+		logger.Info("Waiting for all nodes to stop...", "num_nodes", len(list.Items))
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// archive runs a job archiving the wallet secret of a cluster, followed by a job archiving the ledger volume of each
+// of its nodes in order of their ordinal. Jobs are run one at a time, such that a destination volume claim that may
+// only be mounted by a single node at once is never contended for. archive reports whether all jobs have completed.
+func (r *ReconcileWavelet) archive(logger logr.Logger, cluster *waveletv1alpha1.Wavelet) (bool, error) {
+	claims, err := r.listLedgerClaims(cluster)
+
+	if err != nil {
+		logger.Error(err, "Failed to list ledger volume claims.")
+		return false, err
+	}
+
+	var targets []waveletArchiveTarget
+
+	// The wallet secret may not exist should the cluster have been deleted before it was ever reconciled.
+	err = r.client.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: GetWaveletWalletSecretName(cluster)}, new(corev1.Secret))
+
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Failed to query the wallet secret.")
+		return false, err
+	}
+
+	if err == nil {
+		targets = append(targets, getWaveletArchiveWalletTarget(cluster))
+	}
+
+	ledgers := make(map[uint]string, len(claims))
+	var ordinals []uint
+
+	for _, claim := range claims {
+		idx, err := strconv.ParseUint(claim.Name[strings.LastIndex(claim.Name, "-")+1:], 10, 32)
+
+		if err != nil {
+			continue
+		}
+
+		ledgers[uint(idx)] = claim.Name
+		ordinals = append(ordinals, uint(idx))
+	}
+
+	sort.Slice(ordinals, func(i, j int) bool { return ordinals[i] < ordinals[j] })
+
+	for _, idx := range ordinals {
+		targets = append(targets, getWaveletArchiveLedgerTarget(ledgers[idx], idx))
+	}
+
+	for _, target := range targets {
+		job := new(batchv1.Job)
+
+		err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: getWaveletArchiveJobName(cluster, target)}, job)
+
+		if err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to query archive job.", "target", target.name)
+			return false, err
+		}
+
+		if errors.IsNotFound(err) {
+			job = getWaveletArchiveJob(cluster, target)
+
+			if err := controllerutil.SetControllerReference(cluster, job, r.scheme); err != nil {
+				return false, err
+			}
+
+			if err := r.client.Create(context.TODO(), job); err != nil && !errors.IsAlreadyExists(err) {
+				logger.Error(err, "Failed to create archive job.", "target", target.name)
+				return false, err
+			}
+
+			logger.Info("Created archive job.", "job_name", job.Name, "target", target.name)
+
+			return false, nil
+		}
+
+		for _, condition := range job.Status.Conditions {
+			if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+				r.recorder.Eventf(cluster, corev1.EventTypeWarning, "ArchiveFailed", "Job %s failed to archive %s: %s. Delete the job to retry, or unset the archive of the cluster to skip archival.", job.Name, target.name, condition.Message)
+				return false, fmt.Errorf("archive job %s failed: %s", job.Name, condition.Reason)
+			}
+		}
+
+		if job.Status.Succeeded == 0 {
+			logger.Info("Waiting for archive job to complete...", "job_name", job.Name, "target", target.name)
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// listLedgerClaims returns the ledger volume claims of all nodes in a cluster.
+func (r *ReconcileWavelet) listLedgerClaims(cluster *waveletv1alpha1.Wavelet) ([]corev1.PersistentVolumeClaim, error) {
+	claims := new(corev1.PersistentVolumeClaimList)
+
+	opts := &client.ListOptions{Namespace: cluster.Namespace, LabelSelector: labels.SelectorFromSet(LabelsForWavelet(cluster.Name, "node"))}
+
+	if err := r.client.List(context.TODO(), opts, claims); err != nil {
+		return nil, err
+	}
+
+	return claims.Items, nil
+}
@@ -20,79 +20,343 @@
 package wavelet
 
 import (
+	"crypto/sha512"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"github.com/go-logr/logr"
 	"github.com/perlin-network/noise/edwards25519"
 	"github.com/perlin-network/noise/skademlia"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
 	"github.com/valyala/fastjson"
-	"io/ioutil"
-	"os"
-	"path/filepath"
+	"golang.org/x/crypto/blake2b"
+	"math/bits"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultC1 and DefaultC2 are the S/Kademlia puzzle difficulties keys are generated with should a cluster leave them
+// unset.
+const (
+	DefaultC1 = 16
+	DefaultC2 = 16
 )
 
 const (
-	GenPath = "config"
-	C1      = 16
-	C2      = 16
+	SecretKeyGenesis       = "genesis"
+	SecretKeyWalletPrefix  = "wallet"
+	SecretKeyAccountPrefix = "account"
+	SecretKeyNodePrefix    = "node"
 )
 
-func createGenesis(logger logr.Logger, n uint) (string, error) {
-	if err := os.Mkdir(GenPath, 0644); err != nil && !os.IsExist(err) {
-		if os.IsPermission(err) {
-			return "", fmt.Errorf("failed to get permission to create directory %q to store wallets in", GenPath)
+// BootstrapWallet is the path to the wallet built into the node image, which the bootstrap node is started with.
+const BootstrapWallet = "config/wallet.txt"
+
+// DefaultGenesisConfigMapKey is the key of a genesis ConfigMap holding the genesis JSON file, should a cluster leave it
+// unset.
+const DefaultGenesisConfigMapKey = "genesis.json"
+
+func walletSecretKey(idx uint) string {
+	return fmt.Sprintf("%s%d", SecretKeyWalletPrefix, idx)
+}
+
+func accountSecretKey(idx int) string {
+	return fmt.Sprintf("%s%d", SecretKeyAccountPrefix, idx)
+}
+
+func nodeSecretKey(idx uint) string {
+	return fmt.Sprintf("%s%d", SecretKeyNodePrefix, idx)
+}
+
+func getWaveletPuzzle(cluster *waveletv1alpha1.Wavelet) (int, int) {
+	c1, c2 := int(cluster.Spec.Puzzle.C1), int(cluster.Spec.Puzzle.C2)
+
+	if c1 == 0 {
+		c1 = DefaultC1
+	}
+
+	if c2 == 0 {
+		c2 = DefaultC2
+	}
+
+	return c1, c2
+}
+
+// getWalletKeys returns the secret keys of all wallets generated for a cluster at genesis: n - 1 rich wallets, a
+// wallet for every node in a cluster of a given size that is not assigned a rich wallet, and a wallet for every
+// genesis account that lacks a public key.
+func getWalletKeys(n uint, size uint, accounts []waveletv1alpha1.WaveletGenesisAccount) []string {
+	var keys []string
+
+	for i := uint(1); i < n; i++ { // Exclude 1 wallet because we already include 1 additional wallet by default.
+		keys = append(keys, walletSecretKey(i))
+	}
+
+	keys = append(keys, getNodeWalletKeys(n, size)...)
+
+	for i, account := range accounts {
+		if len(account.PublicKey) == 0 {
+			keys = append(keys, accountSecretKey(i))
+		}
+	}
+
+	return keys
+}
+
+// getNodeWalletKeys returns the secret keys of the wallets of all nodes in a cluster of a given size that are not
+// assigned one of n - 1 rich wallets. The bootstrap node is always started with BootstrapWallet.
+func getNodeWalletKeys(n uint, size uint) []string {
+	var keys []string
+
+	start := n
+
+	if start < 1 {
+		start = 1
+	}
+
+	for i := start; i < size; i++ {
+		keys = append(keys, nodeSecretKey(i))
+	}
+
+	return keys
+}
+
+// wallet is a generated keypair.
+type wallet struct {
+	privateKey []byte // hex-encoded
+	publicKey  string // hex-encoded
+}
+
+// seededReader is an endless stream of bytes derived from a seed and a label, produced by hashing both alongside an
+// incrementing counter. Streams of different labels derived from the same seed are independent of one another.
+type seededReader struct {
+	seed, label string
+	counter     uint64
+	buf         []byte
+}
+
+func (r *seededReader) Read(p []byte) (int, error) {
+	for n := 0; n < len(p); {
+		if len(r.buf) == 0 {
+			var counter [8]byte
+			binary.BigEndian.PutUint64(counter[:], r.counter)
+
+			h := sha512.New()
+			h.Write([]byte(r.seed))
+			h.Write([]byte{0})
+			h.Write([]byte(r.label))
+			h.Write(counter[:])
+
+			r.buf = h.Sum(nil)
+			r.counter++
+		}
+
+		copied := copy(p[n:], r.buf)
+
+		r.buf = r.buf[copied:]
+		n += copied
+	}
+
+	return len(p), nil
+}
+
+// prefixLen returns the number of leading zero bits in buf.
+func prefixLen(buf []byte) int {
+	for i, b := range buf {
+		if b != 0 {
+			return i*8 + bits.LeadingZeros8(b)
+		}
+	}
+
+	return len(buf) * 8
+}
+
+// generateKeys generates a keypair satisfying the S/Kademlia static and dynamic puzzles. Keypairs are generated from
+// fresh randomness should seed be empty, or are otherwise derived deterministically from the seed and the label.
+func generateKeys(seed, label string, c1, c2 int) (*skademlia.Keypair, error) {
+	if len(seed) == 0 {
+		return skademlia.NewKeys(c1, c2)
+	}
+
+	rand := &seededReader{seed: seed, label: label}
+
+	for {
+		publicKey, privateKey, err := edwards25519.GenerateKey(rand)
+
+		if err != nil {
+			return nil, err
+		}
+
+		id := blake2b.Sum256(publicKey[:])
+		checksum := blake2b.Sum256(id[:])
+
+		// Check the static puzzle before loading the keys, as loading keys solves the far costlier dynamic puzzle.
+		if prefixLen(checksum[:]) < c1 {
+			continue
 		}
 
-		return "", fmt.Errorf("an unknown error occured creating directory %q", GenPath)
+		return skademlia.LoadKeys(privateKey, c1, c2)
+	}
+}
+
+// generateWallet generates a new wallet. The keypair of the wallet is derived from the seed and the secret key the
+// wallet is stored under should seed not be empty.
+func generateWallet(seed, key string, c1, c2 int) (wallet, error) {
+	keys, err := generateKeys(seed, key, c1, c2)
+
+	if err != nil {
+		return wallet{}, err
+	}
+
+	privateKey := keys.PrivateKey()
+
+	privateKeyBuf := make([]byte, hex.EncodedLen(edwards25519.SizePrivateKey))
+
+	if n := hex.Encode(privateKeyBuf[:], privateKey[:]); n != hex.EncodedLen(edwards25519.SizePrivateKey) {
+		return wallet{}, errors.New("an unknown error occurred marshaling a newly generated keypairs private key into hex")
+	}
+
+	return wallet{privateKey: privateKeyBuf, publicKey: hex.EncodeToString(privateKey[edwards25519.SizePrivateKey/2:])}, nil
+}
+
+// createGenesis returns a genesis JSON file allocating balances to n - 1 rich wallets, the wallets of all nodes in a
+// cluster of a given size not assigned a rich wallet, and all accounts on top of the genesis JSON file base. It
+// returns alongside the hex-encoded private keys of each wallet keyed by walletSecretKey, nodeSecretKey or
+// accountSecretKey. wallets must hold every wallet listed by getWalletKeys. The genesis returned is a pure function
+// of the parameters given.
+func createGenesis(n uint, size uint, nodeBalance uint64, accounts []waveletv1alpha1.WaveletGenesisAccount, base string, wallets map[string]wallet) (string, map[string][]byte, error) {
+	if len(base) == 0 {
+		base = `{}`
+	}
+
+	genesis, err := fastjson.Parse(base)
+
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to parse base genesis: %v", err)
+	}
+
+	if genesis.Type() != fastjson.TypeObject {
+		return "", nil, errors.New("base genesis must be a JSON object")
 	}
 
-	genesis := fastjson.MustParse(`{}`)
 	balance := fastjson.MustParse(`{"balance": 10000000000000000000}`)
 
-	for i := uint(1); i < n; i++ { // Exclude 1 wallet because we already include 1 additional wallet by default.
-		walletFilePath := filepath.Join(GenPath, fmt.Sprintf("wallet%d.txt", i))
+	privateKeys := make(map[string][]byte)
+
+	for i := uint(1); i < n; i++ {
+		w, exists := wallets[walletSecretKey(i)]
+
+		if !exists {
+			return "", nil, fmt.Errorf("wallet %q was not generated", walletSecretKey(i))
+		}
+
+		privateKeys[walletSecretKey(i)] = w.privateKey
+
+		genesis.Set(w.publicKey, balance)
+	}
+
+	var arena fastjson.Arena
+
+	for _, key := range getNodeWalletKeys(n, size) {
+		w, exists := wallets[key]
 
-		if buf, err := ioutil.ReadFile(walletFilePath); err == nil && len(buf) == hex.EncodedLen(edwards25519.SizePrivateKey) {
-			var privateKey edwards25519.PrivateKey
+		if !exists {
+			return "", nil, fmt.Errorf("wallet %q was not generated", key)
+		}
+
+		privateKeys[key] = w.privateKey
+
+		if nodeBalance > 0 {
+			state := arena.NewObject()
+			state.Set("balance", arena.NewNumberString(strconv.FormatUint(nodeBalance, 10)))
+
+			genesis.Set(w.publicKey, state)
+		}
+	}
+
+	for i, account := range accounts {
+		publicKey := account.PublicKey
+
+		if len(publicKey) == 0 {
+			w, exists := wallets[accountSecretKey(i)]
 
-			if _, err := hex.Decode(privateKey[:], buf); err != nil {
-				return "", err
+			if !exists {
+				return "", nil, fmt.Errorf("wallet %q was not generated", accountSecretKey(i))
 			}
 
-			genesis.Set(
-				hex.EncodeToString(privateKey[edwards25519.SizePrivateKey/2:]),
-				balance,
-			)
+			privateKeys[accountSecretKey(i)] = w.privateKey
+			publicKey = w.publicKey
+		}
 
-			continue
+		state := arena.NewObject()
+
+		state.Set("balance", arena.NewNumberString(strconv.FormatUint(account.Balance, 10)))
+
+		if account.Stake > 0 {
+			state.Set("stake", arena.NewNumberString(strconv.FormatUint(account.Stake, 10)))
 		}
 
-		keys, err := skademlia.NewKeys(C1, C2)
+		if account.Reward > 0 {
+			state.Set("reward", arena.NewNumberString(strconv.FormatUint(account.Reward, 10)))
+		}
 
-		if err != nil {
-			return "", err
+		genesis.Set(publicKey, state)
+	}
+
+	return genesis.String(), privateKeys, nil
+}
+
+// getWaveletNodeWalletKey returns the key of the wallet secret entry holding the wallet assigned to the node with a
+// given ordinal, should the node not be the bootstrap node and its wallet have been generated.
+func getWaveletNodeWalletKey(secret *corev1.Secret, idx uint) (string, bool) {
+	if idx == 0 {
+		return "", false
+	}
+
+	for _, key := range []string{walletSecretKey(idx), nodeSecretKey(idx)} {
+		if _, exists := secret.Data[key]; exists {
+			return key, true
 		}
+	}
 
-		privateKey := keys.PrivateKey()
+	return "", false
+}
 
-		privateKeyBuf := make([]byte, hex.EncodedLen(edwards25519.SizePrivateKey))
+// getWaveletNodeWallets describes the wallet assigned to every node in a cluster of a given size.
+func getWaveletNodeWallets(cluster *waveletv1alpha1.Wavelet, secret *corev1.Secret, size uint) []waveletv1alpha1.WaveletNodeWalletStatus {
+	genesis, err := fastjson.ParseBytes(secret.Data[SecretKeyGenesis])
 
-		if n := hex.Encode(privateKeyBuf[:], privateKey[:]); n != hex.EncodedLen(edwards25519.SizePrivateKey) {
-			return "", errors.New("an unknown error occurred marshaling a newly generated keypairs private key into hex")
+	if err != nil {
+		genesis = nil
+	}
+
+	wallets := make([]waveletv1alpha1.WaveletNodeWalletStatus, 0, size)
+
+	for idx := uint(0); idx < size; idx++ {
+		status := waveletv1alpha1.WaveletNodeWalletStatus{
+			Node: fmt.Sprintf("%s-%d", cluster.Name, idx),
 		}
 
-		if err := ioutil.WriteFile(walletFilePath, privateKeyBuf, 0644); err != nil {
-			return "", err
+		status.Wallet, _ = GetWaveletNodeWallet(secret, idx)
+
+		if idx == 0 {
+			// The bootstrap wallet is allocated its balance by the default genesis of the node image.
+			status.Funded = true
 		}
 
-		logger.Info("Generated a wallet.", "path", walletFilePath)
+		if key, exists := getWaveletNodeWalletKey(secret, idx); exists {
+			privateKey := secret.Data[key]
+
+			if len(privateKey) == hex.EncodedLen(edwards25519.SizePrivateKey) {
+				status.PublicKey = string(privateKey[hex.EncodedLen(edwards25519.SizePrivateKey/2):])
+			}
+
+			status.Funded = genesis != nil && genesis.Exists(status.PublicKey, "balance") && genesis.GetUint64(status.PublicKey, "balance") > 0
+		}
 
-		genesis.Set(
-			hex.EncodeToString(privateKey[edwards25519.SizePrivateKey/2:]),
-			balance,
-		)
+		wallets = append(wallets, status)
 	}
 
-	return genesis.String(), nil
+	return wallets
 }
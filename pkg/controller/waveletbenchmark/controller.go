@@ -0,0 +1,548 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package waveletbenchmark
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-logr/logr"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"github.com/perlin-network/wavelet-operator/pkg/controller/wavelet"
+	"k8s.io/apimachinery/pkg/labels"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("waveletbenchmark.controller")
+
+// Add creates a new WaveletBenchmark Controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	r, err := newReconciler(mgr)
+
+	if err != nil {
+		return err
+	}
+
+	return add(mgr, r)
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) (*ReconcileWaveletBenchmark, error) {
+	// The client provided by the manager is unable to read the logs of pods, which results are collected from.
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReconcileWaveletBenchmark{
+		client:   mgr.GetClient(),
+		pods:     clientset.CoreV1(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetRecorder("waveletbenchmark-controller"),
+		http:     &http.Client{Timeout: ledgerQueryTimeout},
+	}, nil
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileWaveletBenchmark) error {
+	c, err := controller.New("waveletbenchmark-controller", mgr, controller.Options{Reconciler: r})
+
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: new(waveletv1alpha1.WaveletBenchmark)}, new(handler.EnqueueRequestForObject))
+
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: new(corev1.Pod)}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    new(waveletv1alpha1.WaveletBenchmark),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// Scheduled benchmarks own a WaveletBenchmark for each of their runs, and keep track of their progress.
+	err = c.Watch(&source.Kind{Type: new(waveletv1alpha1.WaveletBenchmark)}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    new(waveletv1alpha1.WaveletBenchmark),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// Benchmarks wait on the cluster they reference and its node pods to be ready and healthy, and point their
+	// workers at the stable DNS names of its nodes (see wavelet.GetWaveletNodeHost), so changes to either are mapped
+	// back to every benchmark referencing the cluster.
+	err = c.Watch(&source.Kind{Type: new(waveletv1alpha1.Wavelet)}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.mapClusterToBenchmarks)})
+
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: new(corev1.Pod)}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.mapNodeToBenchmarks)})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *ReconcileWaveletBenchmark) mapClusterToBenchmarks(obj handler.MapObject) []reconcile.Request {
+	return r.listBenchmarkRequests(obj.Meta.GetNamespace(), obj.Meta.GetName())
+}
+
+func (r *ReconcileWaveletBenchmark) mapNodeToBenchmarks(obj handler.MapObject) []reconcile.Request {
+	podLabels := obj.Meta.GetLabels()
+
+	if podLabels["role"] != "node" {
+		return nil
+	}
+
+	name, exists := podLabels["app"]
+
+	if !exists {
+		return nil
+	}
+
+	return r.listBenchmarkRequests(obj.Meta.GetNamespace(), name)
+}
+
+// listBenchmarkRequests returns a request for every benchmark that references a given cluster.
+func (r *ReconcileWaveletBenchmark) listBenchmarkRequests(namespace, cluster string) []reconcile.Request {
+	list := new(waveletv1alpha1.WaveletBenchmarkList)
+
+	if err := r.client.List(context.TODO(), &client.ListOptions{Namespace: namespace}, list); err != nil {
+		log.Error(err, "Failed to list benchmarks referencing a cluster.", "namespace", namespace, "cluster", cluster)
+		return nil
+	}
+
+	var requests []reconcile.Request
+
+	for _, benchmark := range list.Items {
+		if benchmark.Spec.Cluster == cluster {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: benchmark.Namespace, Name: benchmark.Name}})
+		}
+	}
+
+	return requests
+}
+
+var _ reconcile.Reconciler = &ReconcileWaveletBenchmark{}
+
+type ReconcileWaveletBenchmark struct {
+	client   client.Client
+	pods     corev1client.PodsGetter
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+	http     *http.Client
+}
+
+func (r *ReconcileWaveletBenchmark) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	logger := log.WithValues("request.namespace", request.Namespace, "request.name", request.Name)
+
+	benchmark := new(waveletv1alpha1.WaveletBenchmark)
+
+	if err := r.client.Get(context.TODO(), request.NamespacedName, benchmark); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	original := benchmark.Status.DeepCopy()
+
+	result, err := r.reconcile(logger, benchmark)
+
+	if err := r.updateStatus(benchmark, original, err); err != nil {
+		logger.Error(err, "Failed to update the status of the benchmark.")
+		return reconcile.Result{}, err
+	}
+
+	return result, err
+}
+
+// listWorkers returns all worker pods of a benchmark that are not in the middle of being deleted.
+func (r *ReconcileWaveletBenchmark) listWorkers(benchmark *waveletv1alpha1.WaveletBenchmark) ([]corev1.Pod, error) {
+	list := new(corev1.PodList)
+
+	opts := &client.ListOptions{Namespace: benchmark.Namespace, LabelSelector: labels.SelectorFromSet(labelsForBenchmark(benchmark, "worker"))}
+
+	if err := r.client.List(context.TODO(), opts, list); err != nil {
+		return nil, err
+	}
+
+	var pods []corev1.Pod
+
+	for _, pod := range list.Items {
+		if pod.GetObjectMeta().GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		pods = append(pods, pod)
+	}
+
+	return pods, nil
+}
+
+// listNodes returns all node pods of a cluster that are not in the middle of being deleted, keyed by their ordinal.
+func (r *ReconcileWaveletBenchmark) listNodes(cluster *waveletv1alpha1.Wavelet) (map[uint]corev1.Pod, error) {
+	list := new(corev1.PodList)
+
+	opts := &client.ListOptions{Namespace: cluster.Namespace, LabelSelector: labels.SelectorFromSet(wavelet.LabelsForWavelet(cluster.Name, "node"))}
+
+	if err := r.client.List(context.TODO(), opts, list); err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[uint]corev1.Pod, len(list.Items))
+
+	for _, pod := range list.Items {
+		if pod.GetObjectMeta().GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		if idx, ok := wavelet.GetWaveletPodOrdinal(cluster.Name, pod); ok {
+			nodes[idx] = pod
+		}
+	}
+
+	return nodes, nil
+}
+
+// deleteWorkers deletes all worker pods of a benchmark.
+func (r *ReconcileWaveletBenchmark) deleteWorkers(logger logr.Logger, benchmark *waveletv1alpha1.WaveletBenchmark) error {
+	workers, err := r.listWorkers(benchmark)
+
+	if err != nil {
+		logger.Error(err, "Failed to list all worker pods of the benchmark.")
+		return err
+	}
+
+	for _, worker := range workers {
+		if err := r.client.Delete(context.TODO(), &worker, client.GracePeriodSeconds(0)); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete worker pod.", "pod_name", worker.Name)
+			return err
+		}
+
+		logger.Info("Deleted worker pod.", "pod_name", worker.Name)
+	}
+
+	return nil
+}
+
+// finish moves a benchmark into a terminal phase.
+func (r *ReconcileWaveletBenchmark) finish(benchmark *waveletv1alpha1.WaveletBenchmark, phase waveletv1alpha1.WaveletBenchmarkPhase, reason, message string) {
+	now := metav1.Now()
+
+	benchmark.Status.Phase = phase
+	benchmark.Status.Reason = reason
+	benchmark.Status.Message = message
+	benchmark.Status.CompletionTime = &now
+
+	eventType := corev1.EventTypeNormal
+
+	if phase == waveletv1alpha1.WaveletBenchmarkFailed {
+		eventType = corev1.EventTypeWarning
+	}
+
+	r.recorder.Event(benchmark, eventType, reason, message)
+}
+
+// complete publishes the results of a finished benchmark should it have started, and deletes its workers once done.
+// Samples are collected from the logs of workers, so workers are kept around until their results are published.
+func (r *ReconcileWaveletBenchmark) complete(logger logr.Logger, benchmark *waveletv1alpha1.WaveletBenchmark) error {
+	if benchmark.Status.StartTime != nil && benchmark.Status.Results == nil {
+		if err := r.publishResults(logger, benchmark); err != nil {
+			return err
+		}
+	}
+
+	return r.deleteWorkers(logger, benchmark)
+}
+
+func (r *ReconcileWaveletBenchmark) reconcile(logger logr.Logger, benchmark *waveletv1alpha1.WaveletBenchmark) (reconcile.Result, error) {
+	if isBenchmarkFinished(benchmark) {
+		return reconcile.Result{}, r.complete(logger, benchmark)
+	}
+
+	if len(benchmark.Spec.Schedule) > 0 {
+		return r.reconcileSchedule(logger, benchmark)
+	}
+
+	started := benchmark.Status.StartTime != nil
+
+	if benchmark.Spec.Stop {
+		if started {
+			r.finish(benchmark, waveletv1alpha1.WaveletBenchmarkStopped, "Stopped", "The benchmark was stopped before its duration elapsed.")
+		} else {
+			r.finish(benchmark, waveletv1alpha1.WaveletBenchmarkStopped, "Stopped", "The benchmark was stopped before it started.")
+		}
+
+		return reconcile.Result{}, r.complete(logger, benchmark)
+	}
+
+	cluster := new(waveletv1alpha1.Wavelet)
+
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: benchmark.Namespace, Name: benchmark.Spec.Cluster}, cluster); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	} else if errors.IsNotFound(err) || cluster.GetDeletionTimestamp() != nil {
+		message := fmt.Sprintf("Cluster %q does not exist.", benchmark.Spec.Cluster)
+
+		if !started {
+			setPending(benchmark, "ClusterNotFound", message)
+			return reconcile.Result{}, nil
+		}
+
+		r.finish(benchmark, waveletv1alpha1.WaveletBenchmarkFailed, "ClusterNotFound", message)
+
+		return reconcile.Result{}, r.complete(logger, benchmark)
+	}
+
+	if started {
+		workers, err := r.listWorkers(benchmark)
+
+		if err != nil {
+			logger.Error(err, "Failed to list all worker pods of the benchmark.")
+			return reconcile.Result{}, err
+		}
+
+		if message, failed := getWorkerFailure(workers); failed {
+			r.finish(benchmark, waveletv1alpha1.WaveletBenchmarkFailed, "WorkerFailed", message)
+			return reconcile.Result{}, r.complete(logger, benchmark)
+		}
+	}
+
+	if started && benchmark.Spec.Duration != nil {
+		if remaining := time.Until(benchmark.Status.StartTime.Add(benchmark.Spec.Duration.Duration)); remaining <= 0 {
+			r.finish(benchmark, waveletv1alpha1.WaveletBenchmarkCompleted, "Completed", fmt.Sprintf("The benchmark ran for %s.", benchmark.Spec.Duration.Duration))
+			return reconcile.Result{}, r.complete(logger, benchmark)
+		}
+	}
+
+	expectedNumWorkers := uint(benchmark.Spec.Workers)
+
+	if !started {
+		if cluster.Status.Phase != waveletv1alpha1.WaveletPhaseReady && cluster.Status.Phase != waveletv1alpha1.WaveletPhaseBenchmarking {
+			setPending(benchmark, "ClusterNotReady", fmt.Sprintf("Waiting for cluster %q to be ready.", cluster.Name))
+			return reconcile.Result{}, nil
+		}
+	}
+
+	secret := new(corev1.Secret)
+
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: wavelet.GetWaveletWalletSecretName(cluster)}, secret); err != nil {
+		if errors.IsNotFound(err) && !started {
+			setPending(benchmark, "ClusterNotReady", fmt.Sprintf("Waiting for the wallets of cluster %q to be generated.", cluster.Name))
+			return reconcile.Result{}, nil
+		}
+
+		logger.Error(err, "Failed to get the wallet secret of the cluster.")
+		return reconcile.Result{}, err
+	}
+
+	nodes, err := r.listNodes(cluster)
+
+	if err != nil {
+		logger.Error(err, "Failed to list all node pods of the cluster.")
+		return reconcile.Result{}, err
+	}
+
+	targets, err := wavelet.GetWaveletBenchmarkTargets(benchmark.Spec.Target, benchmark.Name, expectedNumWorkers, uint(cluster.Spec.Size), nodes)
+
+	if err != nil {
+		r.finish(benchmark, waveletv1alpha1.WaveletBenchmarkFailed, "InvalidTarget", err.Error())
+		return reconcile.Result{}, r.complete(logger, benchmark)
+	}
+
+	if !started {
+		if len(targets) == 0 {
+			setPending(benchmark, "NoTargets", fmt.Sprintf("Waiting for a node of cluster %q to match the target of the benchmark.", cluster.Name))
+			return reconcile.Result{}, nil
+		}
+
+		for _, idx := range targets {
+			node, exists := nodes[idx]
+
+			if !exists || !wavelet.IsPodReady(node) {
+				setPending(benchmark, "NodesNotReady", fmt.Sprintf("Waiting for node %d of cluster %q to be ready.", idx, cluster.Name))
+				return reconcile.Result{}, nil
+			}
+
+			// Nodes respond to their readiness probe whether or not they are in sync with their peers, which is
+			// instead judged by the health checks of the wavelet controller.
+			if !wavelet.IsNodeHealthy(cluster, node) {
+				setPending(benchmark, "NodesNotHealthy", fmt.Sprintf("Waiting for node %d of cluster %q to be healthy.", idx, cluster.Name))
+				return reconcile.Result{}, nil
+			}
+		}
+
+		for client, idx := range targets {
+			if _, exists := wavelet.GetWaveletBenchmarkWallet(benchmark.Spec.Target, secret, uint(client), idx, uint(cluster.Spec.Size)); !exists {
+				setPending(benchmark, "WalletsNotReady", fmt.Sprintf("Waiting for the wallet of worker %d to be generated by cluster %q.", client, cluster.Name))
+				return reconcile.Result{}, nil
+			}
+		}
+
+		// The nonces of the wallets of workers are recorded before any worker is created, such that the transactions
+		// accepted by the cluster may be measured from how far they advanced once the benchmark finishes.
+		wallets, err := r.getStartingWallets(benchmark, cluster, nodes, targets, nodes[targets[0]])
+
+		if err != nil {
+			logger.Error(err, "Failed to query the nonces of the wallets of workers.")
+			return reconcile.Result{}, err
+		}
+
+		now := metav1.Now()
+
+		benchmark.Status.Wallets = wallets
+		benchmark.Status.Phase = waveletv1alpha1.WaveletBenchmarkRunning
+		benchmark.Status.StartTime = &now
+
+		r.recorder.Eventf(benchmark, corev1.EventTypeNormal, "Started", "Started %d workers against cluster %q.", expectedNumWorkers, cluster.Name)
+	}
+
+	benchmark.Status.Reason = "Running"
+	benchmark.Status.Message = fmt.Sprintf("%d workers are running against cluster %q.", expectedNumWorkers, cluster.Name)
+
+	if err := r.ensureWorkers(logger, benchmark, cluster, secret, nodes, targets); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if benchmark.Spec.Duration != nil {
+		return reconcile.Result{RequeueAfter: time.Until(benchmark.Status.StartTime.Add(benchmark.Spec.Duration.Duration))}, nil
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// ensureWorkers creates a worker pod for every client of the benchmark whose target node is ready. targets holds the
+// ordinal of the node each client targets. Worker pods are not managed by a controller that rolls them out, so worker
+// pods that drifted from their desired spec (i.e. as they were assigned another node) are deleted and recreated, but
+// only for as long as they have yet to start. Results are read from the logs of workers once the run ends, so workers
+// that started are left running until then.
+func (r *ReconcileWaveletBenchmark) ensureWorkers(logger logr.Logger, benchmark *waveletv1alpha1.WaveletBenchmark, cluster *waveletv1alpha1.Wavelet, secret *corev1.Secret, nodes map[uint]corev1.Pod, targets []uint) error {
+	workers, err := r.listWorkers(benchmark)
+
+	if err != nil {
+		logger.Error(err, "Failed to list all worker pods of the benchmark.")
+		return err
+	}
+
+	expectedNumWorkers := uint(len(targets))
+
+	// Worker pods are only rendered once the wallet they sign transactions with has been generated.
+	desiredWorker := func(idx uint) *corev1.Pod {
+		wallet, exists := wavelet.GetWaveletBenchmarkWallet(benchmark.Spec.Target, secret, idx, targets[idx], uint(cluster.Spec.Size))
+
+		if !exists {
+			return nil
+		}
+
+		return getBenchmarkWorkerPod(benchmark, cluster, targets[idx], wallet, idx)
+	}
+
+	existing := make(map[uint]struct{}, len(workers))
+
+	for _, worker := range workers {
+		idx, ok := wavelet.GetWaveletPodOrdinal(getBenchmarkWorkerPodPrefix(benchmark), worker)
+		ok = ok && idx < expectedNumWorkers
+
+		if ok {
+			if desired := desiredWorker(idx); desired != nil && worker.Annotations[wavelet.AnnotationSpecHash] == desired.Annotations[wavelet.AnnotationSpecHash] {
+				existing[idx] = struct{}{}
+				continue
+			}
+		}
+
+		if hasContainerStarted(worker) {
+			if ok {
+				existing[idx] = struct{}{}
+			}
+
+			logger.Info("Leaving drifted worker pod running until the benchmark ends.", "pod_name", worker.Name)
+			continue
+		}
+
+		if err := r.client.Delete(context.TODO(), &worker); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete worker pod.", "pod_name", worker.Name)
+			return err
+		}
+
+		logger.Info("Deleted worker pod.", "pod_name", worker.Name)
+	}
+
+	for idx := uint(0); idx < expectedNumWorkers; idx++ {
+		if _, exists := existing[idx]; exists {
+			continue
+		}
+
+		if node, exists := nodes[targets[idx]]; !exists || !wavelet.IsPodReady(node) {
+			logger.Info("Waiting for node to be ready before creating its worker...", "node_idx", targets[idx])
+			continue
+		}
+
+		worker := desiredWorker(idx)
+
+		if worker == nil {
+			logger.Info("Waiting for the wallet of worker to be generated...", "idx", idx, "node_idx", targets[idx])
+			continue
+		}
+
+		if err := controllerutil.SetControllerReference(benchmark, worker, r.scheme); err != nil {
+			return err
+		}
+
+		if err := r.client.Create(context.TODO(), worker); err != nil && !errors.IsAlreadyExists(err) {
+			logger.Error(err, "Failed to create worker pod.", "idx", idx)
+			return err
+		}
+
+		logger.Info("Created worker pod.", "pod_name", worker.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package waveletbenchmark
+
+import (
+	"testing"
+	"time"
+)
+
+func bits(values ...int) uint64 {
+	var b uint64
+
+	for _, value := range values {
+		b |= 1 << uint(value)
+	}
+
+	return b
+}
+
+func TestParseCronField(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    string
+		min, max int
+		expected uint64
+	}{
+		{name: "wildcard", field: "*", min: 1, max: 12, expected: bits(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12)},
+		{name: "value", field: "5", min: 0, max: 59, expected: bits(5)},
+		{name: "range", field: "1-3", min: 0, max: 59, expected: bits(1, 2, 3)},
+		{name: "wildcard step", field: "*/20", min: 0, max: 59, expected: bits(0, 20, 40)},
+		{name: "value step", field: "5/15", min: 0, max: 59, expected: bits(5, 20, 35, 50)},
+		{name: "range step", field: "10-20/5", min: 0, max: 59, expected: bits(10, 15, 20)},
+		{name: "list", field: "1,3-4,*/30", min: 0, max: 59, expected: bits(0, 1, 3, 4, 30)},
+		{name: "bounds", field: "0,59", min: 0, max: 59, expected: bits(0, 59)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := parseCronField(test.field, test.min, test.max)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if actual != test.expected {
+				t.Errorf("expected %b, got %b", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseCronFieldInvalid(t *testing.T) {
+	for _, field := range []string{"", "a", "60", "-1", "5-3", "1-b", "1-60", "*/0", "*/-1", "*/a", "1,,2"} {
+		t.Run(field, func(t *testing.T) {
+			if _, err := parseCronField(field, 0, 59); err == nil {
+				t.Errorf("expected %q to be rejected", field)
+			}
+		})
+	}
+}
+
+func TestParseCronSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected cronSchedule
+	}{
+		{
+			name: "descriptor",
+			spec: "@hourly",
+			expected: cronSchedule{
+				minute: bits(0), hour: (1 << 24) - 1, dom: ((1 << 32) - 1) &^ 1, month: ((1 << 13) - 1) &^ 1, dow: (1 << 8) - 1,
+				domStar: true, dowStar: true,
+			},
+		},
+		{
+			name: "surrounding whitespace",
+			spec: "  30 9 1 1 1  ",
+			expected: cronSchedule{
+				minute: bits(30), hour: bits(9), dom: bits(1), month: bits(1), dow: bits(1),
+			},
+		},
+		{
+			name: "sunday as 7",
+			spec: "0 0 1 1 7",
+			expected: cronSchedule{
+				minute: bits(0), hour: bits(0), dom: bits(1), month: bits(1), dow: bits(0, 7),
+			},
+		},
+		{
+			name: "restricted wildcard day of month",
+			spec: "0 0 */2 1 1",
+			expected: cronSchedule{
+				minute: bits(0), hour: bits(0), dom: bits(1, 3, 5, 7, 9, 11, 13, 15, 17, 19, 21, 23, 25, 27, 29, 31), month: bits(1), dow: bits(1),
+				domStar: true,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := parseCronSchedule(test.spec)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if *actual != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, *actual)
+			}
+		})
+	}
+}
+
+func TestParseCronScheduleInvalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "* * * * * *", "@every 5m", "@fortnightly", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * 32 * *", "* * * 0 *", "* * * 13 *", "* * * * 8"} {
+		t.Run(spec, func(t *testing.T) {
+			if _, err := parseCronSchedule(spec); err == nil {
+				t.Errorf("expected %q to be rejected", spec)
+			}
+		})
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	date := func(year int, month time.Month, day, hour, min, sec int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+	}
+
+	// 1st of May 2019 is a Wednesday.
+	tests := []struct {
+		name     string
+		spec     string
+		from     time.Time
+		expected time.Time
+	}{
+		{name: "every minute", spec: "* * * * *", from: date(2019, 5, 1, 10, 7, 30), expected: date(2019, 5, 1, 10, 8, 0)},
+		{name: "strictly after", spec: "0 * * * *", from: date(2019, 5, 1, 10, 0, 0), expected: date(2019, 5, 1, 11, 0, 0)},
+		{name: "minute step", spec: "*/15 * * * *", from: date(2019, 5, 1, 10, 7, 30), expected: date(2019, 5, 1, 10, 15, 0)},
+		{name: "next day", spec: "@daily", from: date(2019, 5, 1, 23, 59, 0), expected: date(2019, 5, 2, 0, 0, 0)},
+		{name: "next year", spec: "@yearly", from: date(2019, 12, 31, 23, 59, 59), expected: date(2020, 1, 1, 0, 0, 0)},
+		{name: "weekdays skip weekend", spec: "30 9 * * 1-5", from: date(2019, 5, 3, 10, 0, 0), expected: date(2019, 5, 6, 9, 30, 0)},
+		{name: "sunday as 7", spec: "0 0 * * 7", from: date(2019, 5, 1, 0, 0, 0), expected: date(2019, 5, 5, 0, 0, 0)},
+		{name: "month step", spec: "0 12 1 */3 *", from: date(2019, 5, 1, 0, 0, 0), expected: date(2019, 7, 1, 12, 0, 0)},
+		{name: "day of month alone", spec: "0 0 13 * *", from: date(2019, 5, 1, 0, 0, 0), expected: date(2019, 5, 13, 0, 0, 0)},
+		{name: "day of month or day of week", spec: "0 0 13 * 5", from: date(2019, 5, 1, 0, 0, 0), expected: date(2019, 5, 3, 0, 0, 0)},
+		{name: "leap day", spec: "0 0 29 2 *", from: date(2019, 3, 1, 0, 0, 0), expected: date(2020, 2, 29, 0, 0, 0)},
+		{name: "never", spec: "0 0 30 2 *", from: date(2019, 5, 1, 0, 0, 0), expected: time.Time{}},
+		{name: "converted to utc", spec: "@daily", from: time.Date(2019, 5, 1, 1, 30, 0, 0, time.FixedZone("UTC+2", 2*60*60)), expected: date(2019, 5, 1, 0, 0, 0)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := parseCronSchedule(test.spec)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if actual := schedule.next(test.from); !actual.Equal(test.expected) {
+				t.Errorf("expected %s, got %s", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package waveletbenchmark
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-logr/logr"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"github.com/perlin-network/wavelet-operator/pkg/controller/wavelet"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ledgerQueryTimeout bounds the time taken by a single query to the HTTP API of a node.
+const ledgerQueryTimeout = 5 * time.Second
+
+// accountsPath is the path of the endpoint of the HTTP API of a node reporting the state of the account with the
+// public key following it.
+const accountsPath = "/accounts/"
+
+// nodeLedger is the subset of the response of wavelet.WaveletLedgerPath a benchmark relies on.
+type nodeLedger struct {
+	// PublicKey is the public key of the wallet the node was started with.
+	PublicKey string `json:"public_key"`
+}
+
+// nodeAccount is the subset of the response of accountsPath a benchmark relies on.
+type nodeAccount struct {
+	// Nonce is the number of transactions created by the account that were applied to the ledger.
+	Nonce uint64 `json:"nonce"`
+}
+
+// walletResult is the number of transactions accepted by the cluster that were submitted with a single wallet.
+type walletResult struct {
+	PublicKey string `json:"public_key"`
+	Accepted  uint64 `json:"accepted"`
+}
+
+// queryNode decodes the JSON response of an endpoint of the HTTP API of a node into v.
+func (r *ReconcileWaveletBenchmark) queryNode(node corev1.Pod, path string, v interface{}) error {
+	res, err := r.http.Get("http://" + net.JoinHostPort(node.Status.PodIP, "9000") + path)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with status %s", path, res.Status)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode the response of %s: %v", path, err)
+	}
+
+	return nil
+}
+
+// queryNonces queries the nonces of a list of wallets from the ledger of a node, keyed by their public key.
+func (r *ReconcileWaveletBenchmark) queryNonces(node corev1.Pod, publicKeys []string) (map[string]uint64, error) {
+	nonces := make(map[string]uint64, len(publicKeys))
+
+	for _, publicKey := range publicKeys {
+		var account nodeAccount
+
+		if err := r.queryNode(node, accountsPath+publicKey, &account); err != nil {
+			return nil, err
+		}
+
+		nonces[publicKey] = account.Nonce
+	}
+
+	return nonces, nil
+}
+
+// getWalletPublicKey returns the public key of the wallet of the node with a given ordinal. It is read from the status
+// of the cluster should it be known, and queried from the node itself otherwise, as is the case for the wallet built
+// into the node image.
+func (r *ReconcileWaveletBenchmark) getWalletPublicKey(cluster *waveletv1alpha1.Wavelet, nodes map[uint]corev1.Pod, idx uint) (string, error) {
+	name := fmt.Sprintf("%s-%d", cluster.Name, idx)
+
+	for _, status := range cluster.Status.Wallets {
+		if status.Node == name && len(status.PublicKey) > 0 {
+			return status.PublicKey, nil
+		}
+	}
+
+	node, exists := nodes[idx]
+
+	if !exists || !wavelet.IsPodReady(node) {
+		return "", fmt.Errorf("node %d is not ready to report the public key of its wallet", idx)
+	}
+
+	var ledger nodeLedger
+
+	if err := r.queryNode(node, wavelet.WaveletLedgerPath, &ledger); err != nil {
+		return "", err
+	}
+
+	if len(ledger.PublicKey) == 0 {
+		return "", fmt.Errorf("node %d did not report the public key of its wallet", idx)
+	}
+
+	return ledger.PublicKey, nil
+}
+
+// getStartingWallets returns the wallets workers of a benchmark submit transactions with alongside their current
+// nonce, as queried from the ledger of a given node. targets holds the ordinal of the node each worker targets.
+func (r *ReconcileWaveletBenchmark) getStartingWallets(benchmark *waveletv1alpha1.WaveletBenchmark, cluster *waveletv1alpha1.Wavelet, nodes map[uint]corev1.Pod, targets []uint, node corev1.Pod) ([]waveletv1alpha1.WaveletBenchmarkWalletStatus, error) {
+	seen := make(map[uint]struct{}, len(targets))
+
+	var publicKeys []string
+
+	for client, idx := range targets {
+		owner := wavelet.GetWaveletBenchmarkWalletNode(benchmark.Spec.Target, uint(client), idx, uint(cluster.Spec.Size))
+
+		if _, exists := seen[owner]; exists {
+			continue
+		}
+
+		seen[owner] = struct{}{}
+
+		publicKey, err := r.getWalletPublicKey(cluster, nodes, owner)
+
+		if err != nil {
+			return nil, err
+		}
+
+		publicKeys = append(publicKeys, publicKey)
+	}
+
+	sort.Strings(publicKeys)
+
+	nonces, err := r.queryNonces(node, publicKeys)
+
+	if err != nil {
+		return nil, err
+	}
+
+	wallets := make([]waveletv1alpha1.WaveletBenchmarkWalletStatus, 0, len(publicKeys))
+
+	for _, publicKey := range publicKeys {
+		wallets = append(wallets, waveletv1alpha1.WaveletBenchmarkWalletStatus{PublicKey: publicKey, Nonce: nonces[publicKey]})
+	}
+
+	return wallets, nil
+}
+
+// countAcceptedTransactions returns the number of transactions accepted by the cluster that were submitted with each
+// wallet, given the nonces of the wallets when the benchmark started and finished.
+func countAcceptedTransactions(wallets []waveletv1alpha1.WaveletBenchmarkWalletStatus, nonces map[string]uint64) ([]walletResult, uint64, error) {
+	results := make([]walletResult, 0, len(wallets))
+
+	var accepted uint64
+
+	for _, wallet := range wallets {
+		nonce, exists := nonces[wallet.PublicKey]
+
+		if !exists {
+			return nil, 0, fmt.Errorf("the nonce of wallet %s is unknown", wallet.PublicKey)
+		}
+
+		// The ledger of the cluster was reset over the course of the benchmark (i.e. as its nodes were wiped).
+		if nonce < wallet.Nonce {
+			return nil, 0, fmt.Errorf("the nonce of wallet %s went back from %d to %d", wallet.PublicKey, wallet.Nonce, nonce)
+		}
+
+		results = append(results, walletResult{PublicKey: wallet.PublicKey, Accepted: nonce - wallet.Nonce})
+		accepted += nonce - wallet.Nonce
+	}
+
+	return results, accepted, nil
+}
+
+// measureLedger measures the number of transactions accepted by the cluster of a finished benchmark from how far the
+// nonces of the wallets of its workers advanced, as queried from the first ready and healthy node of the cluster to
+// respond. It reports false should the benchmark not have recorded its wallets, or should no node respond.
+func (r *ReconcileWaveletBenchmark) measureLedger(logger logr.Logger, benchmark *waveletv1alpha1.WaveletBenchmark) ([]walletResult, uint64, bool) {
+	if len(benchmark.Status.Wallets) == 0 {
+		return nil, 0, false
+	}
+
+	cluster := new(waveletv1alpha1.Wavelet)
+
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: benchmark.Namespace, Name: benchmark.Spec.Cluster}, cluster); err != nil {
+		logger.Info("Unable to query the cluster to measure the results of the benchmark from its ledger.", "error", err.Error())
+		return nil, 0, false
+	}
+
+	nodes, err := r.listNodes(cluster)
+
+	if err != nil {
+		logger.Info("Unable to list the nodes of the cluster to measure the results of the benchmark from its ledger.", "error", err.Error())
+		return nil, 0, false
+	}
+
+	ordinals := make([]uint, 0, len(nodes))
+
+	for idx := range nodes {
+		ordinals = append(ordinals, idx)
+	}
+
+	sort.Slice(ordinals, func(i, j int) bool { return ordinals[i] < ordinals[j] })
+
+	publicKeys := make([]string, 0, len(benchmark.Status.Wallets))
+
+	for _, wallet := range benchmark.Status.Wallets {
+		publicKeys = append(publicKeys, wallet.PublicKey)
+	}
+
+	for _, idx := range ordinals {
+		node := nodes[idx]
+
+		if !wavelet.IsNodeAvailable(cluster, node) {
+			continue
+		}
+
+		nonces, err := r.queryNonces(node, publicKeys)
+
+		if err != nil {
+			logger.Info("Failed to query the nonces of the wallets of the benchmark.", "pod_name", node.Name, "error", err.Error())
+			continue
+		}
+
+		wallets, accepted, err := countAcceptedTransactions(benchmark.Status.Wallets, nonces)
+
+		if err != nil {
+			logger.Info("Unable to measure the results of the benchmark from the ledger of the cluster.", "error", err.Error())
+			return nil, 0, false
+		}
+
+		return wallets, accepted, true
+	}
+
+	logger.Info("No node of the cluster responded with the nonces of the wallets of the benchmark.")
+
+	return nil, 0, false
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package waveletbenchmark
+
+import (
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"reflect"
+	"testing"
+)
+
+func TestCountAcceptedTransactions(t *testing.T) {
+	wallets := []waveletv1alpha1.WaveletBenchmarkWalletStatus{{PublicKey: "a", Nonce: 10}, {PublicKey: "b", Nonce: 0}}
+
+	results, accepted, err := countAcceptedTransactions(wallets, map[string]uint64{"a": 25, "b": 7, "c": 100})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if accepted != 22 {
+		t.Errorf("expected 22 accepted transactions, got %d", accepted)
+	}
+
+	if expected := []walletResult{{PublicKey: "a", Accepted: 15}, {PublicKey: "b", Accepted: 7}}; !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected %v, got %v", expected, results)
+	}
+}
+
+func TestCountAcceptedTransactionsInvalid(t *testing.T) {
+	wallets := []waveletv1alpha1.WaveletBenchmarkWalletStatus{{PublicKey: "a", Nonce: 10}}
+
+	tests := []struct {
+		name   string
+		nonces map[string]uint64
+	}{
+		{"missing wallet", map[string]uint64{"b": 10}},
+		{"nonce went back", map[string]uint64{"a": 9}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, _, err := countAcceptedTransactions(wallets, test.nonces); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
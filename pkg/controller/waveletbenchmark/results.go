@@ -0,0 +1,375 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package waveletbenchmark
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-logr/logr"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"io"
+	"math"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReportKey is the key of the report ConfigMap of a benchmark holding its report as JSON.
+const ReportKey = "report.json"
+
+// SampleEvent marks a line logged by a benchmark client as a sample of its results.
+const SampleEvent = "benchmark"
+
+// maxLogLineSize bounds the size of a single line read from the logs of a worker.
+const maxLogLineSize = 1 << 20
+
+// workerSample is a line logged by a benchmark client reporting the transactions it submitted over an interval.
+// Benchmark clients may log samples as JSON objects on their own line, and all other lines are ignored. Samples are
+// optional: the number of transactions accepted by the cluster is measured from its ledger (see measureLedger), and
+// samples only add the errors and latencies observed by clients.
+type workerSample struct {
+	Event     string    `json:"event"`
+	Accepted  uint64    `json:"num_accepted"`
+	Errors    uint64    `json:"num_errors"`
+	Latencies []float64 `json:"latencies_ms"`
+}
+
+// workerResult sums up all samples logged by a single worker.
+type workerResult struct {
+	Worker   string                                  `json:"worker"`
+	Samples  int                                     `json:"samples"`
+	Accepted uint64                                  `json:"accepted"`
+	Errors   uint64                                  `json:"errors"`
+	Latency  waveletv1alpha1.WaveletBenchmarkLatency `json:"latency"`
+
+	latencies []time.Duration
+}
+
+// benchmarkReport is the full report of a finished benchmark, which is published as JSON in its report ConfigMap.
+type benchmarkReport struct {
+	Benchmark      string                                  `json:"benchmark"`
+	Spec           waveletv1alpha1.WaveletBenchmarkSpec    `json:"spec"`
+	Phase          waveletv1alpha1.WaveletBenchmarkPhase   `json:"phase"`
+	StartTime      *metav1.Time                            `json:"start_time,omitempty"`
+	CompletionTime *metav1.Time                            `json:"completion_time,omitempty"`
+	Results        waveletv1alpha1.WaveletBenchmarkResults `json:"results"`
+	Workers        []workerResult                          `json:"workers"`
+	Wallets        []walletResult                          `json:"wallets,omitempty"`
+}
+
+func getBenchmarkReportName(benchmark *waveletv1alpha1.WaveletBenchmark) string {
+	return fmt.Sprintf("%s-report", benchmark.Name)
+}
+
+// parseWorkerLog sums up all samples found in the logs of a worker. Logs of multiple runs of a worker container may
+// be given, in the order the runs took place.
+func parseWorkerLog(worker string, logs ...io.Reader) (workerResult, error) {
+	result := workerResult{Worker: worker}
+
+	for _, r := range logs {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLogLineSize)
+
+		for scanner.Scan() {
+			var sample workerSample
+
+			if err := json.Unmarshal(scanner.Bytes(), &sample); err != nil || sample.Event != SampleEvent {
+				continue
+			}
+
+			result.Samples++
+			result.Accepted += sample.Accepted
+			result.Errors += sample.Errors
+
+			for _, latency := range sample.Latencies {
+				result.latencies = append(result.latencies, time.Duration(latency*float64(time.Millisecond)))
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			return result, err
+		}
+	}
+
+	result.Latency = summarizeLatencies(result.latencies)
+
+	return result, nil
+}
+
+// percentile returns the p-th percentile of a sorted list of latencies using the nearest-rank method.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+func summarizeLatencies(latencies []time.Duration) waveletv1alpha1.WaveletBenchmarkLatency {
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return waveletv1alpha1.WaveletBenchmarkLatency{
+		P50: metav1.Duration{Duration: percentile(sorted, 50)},
+		P90: metav1.Duration{Duration: percentile(sorted, 90)},
+		P99: metav1.Duration{Duration: percentile(sorted, 99)},
+		Max: metav1.Duration{Duration: percentile(sorted, 100)},
+	}
+}
+
+// aggregateResults aggregates the results of all workers of a benchmark that ran for a given amount of time.
+// Latency percentiles are computed over the samples of all workers combined rather than averaged across workers.
+func aggregateResults(workers []workerResult, elapsed time.Duration) waveletv1alpha1.WaveletBenchmarkResults {
+	results := waveletv1alpha1.WaveletBenchmarkResults{Workers: int32(len(workers))}
+
+	var latencies []time.Duration
+
+	for _, worker := range workers {
+		results.Accepted += worker.Accepted
+		results.Errors += worker.Errors
+
+		latencies = append(latencies, worker.latencies...)
+	}
+
+	results.TPS = formatTPS(results.Accepted, elapsed)
+	results.Latency = summarizeLatencies(latencies)
+
+	return results
+}
+
+// formatTPS returns the number of transactions accepted per second over a given amount of time, rounded to two
+// decimal places.
+func formatTPS(accepted uint64, elapsed time.Duration) string {
+	tps := 0.0
+
+	if elapsed > 0 {
+		tps = float64(accepted) / elapsed.Seconds()
+	}
+
+	return strconv.FormatFloat(tps, 'f', 2, 64)
+}
+
+// hasContainerStarted reports whether any container of a pod has ever started, and thus has logs to be read.
+func hasContainerStarted(pod corev1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running != nil || status.State.Terminated != nil || status.LastTerminationState.Terminated != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasContainerRestarted reports whether the wavelet container of a pod has terminated before its latest run, and
+// thus has the logs of its previous run to be read.
+func hasContainerRestarted(pod corev1.Pod) bool {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == "wavelet" && status.LastTerminationState.Terminated != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readWorkerLog reads and parses the logs of the wavelet container of a worker. The logs of the previous run of the
+// container are read as well should it have restarted.
+func (r *ReconcileWaveletBenchmark) readWorkerLog(worker corev1.Pod) (workerResult, error) {
+	var runs []bool
+
+	if hasContainerRestarted(worker) {
+		runs = append(runs, true)
+	}
+
+	runs = append(runs, false)
+
+	logs := make([]io.Reader, 0, len(runs))
+
+	for _, previous := range runs {
+		stream, err := r.pods.Pods(worker.Namespace).GetLogs(worker.Name, &corev1.PodLogOptions{Container: "wavelet", Previous: previous}).Stream()
+
+		if err != nil {
+			return workerResult{Worker: worker.Name}, err
+		}
+
+		defer stream.Close()
+
+		logs = append(logs, stream)
+	}
+
+	return parseWorkerLog(worker.Name, logs...)
+}
+
+// collectResults reads the logs of all workers of a benchmark that have started. Kubernetes only retains the logs of
+// the latest and the previous run of a container, so samples logged by a worker container that restarted more than
+// once are partially lost.
+func (r *ReconcileWaveletBenchmark) collectResults(logger logr.Logger, benchmark *waveletv1alpha1.WaveletBenchmark) ([]workerResult, error) {
+	workers, err := r.listWorkers(benchmark)
+
+	if err != nil {
+		logger.Error(err, "Failed to list all worker pods of the benchmark.")
+		return nil, err
+	}
+
+	results := make([]workerResult, 0, len(workers))
+
+	for _, worker := range workers {
+		if !hasContainerStarted(worker) {
+			logger.Info("Skipping results of worker that never started.", "pod_name", worker.Name)
+			continue
+		}
+
+		result, err := r.readWorkerLog(worker)
+
+		if err != nil {
+			logger.Error(err, "Failed to read the logs of worker pod.", "pod_name", worker.Name)
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Worker < results[j].Worker })
+
+	return results, nil
+}
+
+// getSilentWorkers returns the names of all workers that did not log a single sample of their results.
+func getSilentWorkers(workers []workerResult) []string {
+	var silent []string
+
+	for _, worker := range workers {
+		if worker.Samples == 0 {
+			silent = append(silent, worker.Worker)
+		}
+	}
+
+	return silent
+}
+
+// publishResults collects and aggregates the results of all workers of a finished benchmark, and publishes them in
+// the status of the benchmark and as a report ConfigMap owned by the benchmark. The number of transactions accepted
+// by the cluster is measured from its ledger should it be reachable, and summed from the samples of workers otherwise.
+func (r *ReconcileWaveletBenchmark) publishResults(logger logr.Logger, benchmark *waveletv1alpha1.WaveletBenchmark) error {
+	workers, err := r.collectResults(logger, benchmark)
+
+	if err != nil {
+		return err
+	}
+
+	var elapsed time.Duration
+
+	if benchmark.Status.StartTime != nil && benchmark.Status.CompletionTime != nil {
+		elapsed = benchmark.Status.CompletionTime.Sub(benchmark.Status.StartTime.Time)
+	}
+
+	results := aggregateResults(workers, elapsed)
+	results.Report = getBenchmarkReportName(benchmark)
+
+	wallets, accepted, measured := r.measureLedger(logger, benchmark)
+
+	if measured {
+		results.Accepted = accepted
+		results.TPS = formatTPS(accepted, elapsed)
+	}
+
+	// Workers that logged no samples either ran an image that does not log results in the expected format, or lost
+	// their logs. Their errors and latencies would otherwise silently count as zero.
+	silent := getSilentWorkers(workers)
+
+	for _, worker := range silent {
+		logger.Info("Worker did not log any results.", "pod_name", worker)
+		r.recorder.Eventf(benchmark, corev1.EventTypeWarning, "NoSamples", "Worker %s did not log any results.", worker)
+	}
+
+	// A benchmark that ran for its full duration without any results is failed rather than reported at zero TPS, so
+	// that it is never picked as the baseline of a schedule.
+	if !measured && len(silent) == len(workers) && benchmark.Status.Phase == waveletv1alpha1.WaveletBenchmarkCompleted {
+		benchmark.Status.Phase = waveletv1alpha1.WaveletBenchmarkFailed
+		benchmark.Status.Reason = "NoResults"
+		benchmark.Status.Message = fmt.Sprintf("The ledger of cluster %q could not be queried for the nonces of the wallets of workers, and none of the %d workers of the benchmark logged any results as JSON objects with an event of %q.", benchmark.Spec.Cluster, len(workers), SampleEvent)
+
+		r.recorder.Event(benchmark, corev1.EventTypeWarning, benchmark.Status.Reason, benchmark.Status.Message)
+	}
+
+	report := benchmarkReport{
+		Benchmark:      benchmark.Name,
+		Spec:           benchmark.Spec,
+		Phase:          benchmark.Status.Phase,
+		StartTime:      benchmark.Status.StartTime,
+		CompletionTime: benchmark.Status.CompletionTime,
+		Results:        results,
+		Workers:        workers,
+		Wallets:        wallets,
+	}
+
+	buf, err := json.MarshalIndent(report, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      results.Report,
+			Namespace: benchmark.Namespace,
+			Labels:    labelsForBenchmark(benchmark, "report"),
+		},
+		Data: map[string]string{ReportKey: string(buf)},
+	}
+
+	if err := controllerutil.SetControllerReference(benchmark, configMap, r.scheme); err != nil {
+		return err
+	}
+
+	if err := r.client.Create(context.TODO(), configMap); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			logger.Error(err, "Failed to create the report of the benchmark.")
+			return err
+		}
+
+		if err := r.client.Update(context.TODO(), configMap); err != nil {
+			logger.Error(err, "Failed to update the report of the benchmark.")
+			return err
+		}
+	}
+
+	logger.Info("Published the results of the benchmark.", "report", results.Report, "num_workers", results.Workers, "tps", results.TPS)
+
+	r.recorder.Eventf(benchmark, corev1.EventTypeNormal, "ResultsPublished", "Accepted %d transactions at %s TPS across %d workers. See ConfigMap %q.", results.Accepted, results.TPS, results.Workers, results.Report)
+
+	benchmark.Status.Results = &results
+
+	// Results are persisted right away, as they can not be collected again once all workers are deleted.
+	return r.client.Status().Update(context.TODO(), benchmark)
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package waveletbenchmark
+
+import (
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func durations(ms ...int) []time.Duration {
+	latencies := make([]time.Duration, 0, len(ms))
+
+	for _, m := range ms {
+		latencies = append(latencies, time.Duration(m)*time.Millisecond)
+	}
+
+	return latencies
+}
+
+func latency(p50, p90, p99, max int) waveletv1alpha1.WaveletBenchmarkLatency {
+	return waveletv1alpha1.WaveletBenchmarkLatency{
+		P50: metav1.Duration{Duration: time.Duration(p50) * time.Millisecond},
+		P90: metav1.Duration{Duration: time.Duration(p90) * time.Millisecond},
+		P99: metav1.Duration{Duration: time.Duration(p99) * time.Millisecond},
+		Max: metav1.Duration{Duration: time.Duration(max) * time.Millisecond},
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	hundred := make([]int, 100)
+
+	for i := range hundred {
+		hundred[i] = i + 1
+	}
+
+	tests := []struct {
+		name     string
+		sorted   []time.Duration
+		p        float64
+		expected time.Duration
+	}{
+		{name: "no samples", sorted: nil, p: 50, expected: 0},
+		{name: "one sample p0", sorted: durations(7), p: 0, expected: 7 * time.Millisecond},
+		{name: "one sample p50", sorted: durations(7), p: 50, expected: 7 * time.Millisecond},
+		{name: "one sample p100", sorted: durations(7), p: 100, expected: 7 * time.Millisecond},
+		{name: "hundred samples p0", sorted: durations(hundred...), p: 0, expected: 1 * time.Millisecond},
+		{name: "hundred samples p50", sorted: durations(hundred...), p: 50, expected: 50 * time.Millisecond},
+		{name: "hundred samples p90", sorted: durations(hundred...), p: 90, expected: 90 * time.Millisecond},
+		{name: "hundred samples p99", sorted: durations(hundred...), p: 99, expected: 99 * time.Millisecond},
+		{name: "hundred samples p100", sorted: durations(hundred...), p: 100, expected: 100 * time.Millisecond},
+		{name: "rank rounds up", sorted: durations(1, 2, 3), p: 50, expected: 2 * time.Millisecond},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := percentile(test.sorted, test.p); actual != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSummarizeLatencies(t *testing.T) {
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		expected  waveletv1alpha1.WaveletBenchmarkLatency
+	}{
+		{name: "no samples", latencies: nil, expected: latency(0, 0, 0, 0)},
+		{name: "one sample", latencies: durations(42), expected: latency(42, 42, 42, 42)},
+		{name: "unsorted samples", latencies: durations(50, 10, 40, 20, 30, 60, 100, 90, 80, 70), expected: latency(50, 90, 100, 100)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := summarizeLatencies(test.latencies); actual != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSummarizeLatenciesKeepsInput(t *testing.T) {
+	latencies := durations(3, 1, 2)
+
+	summarizeLatencies(latencies)
+
+	if latencies[0] != 3*time.Millisecond || latencies[1] != 1*time.Millisecond || latencies[2] != 2*time.Millisecond {
+		t.Errorf("expected latencies to be left unsorted, got %v", latencies)
+	}
+}
+
+func TestAggregateResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		workers  []workerResult
+		elapsed  time.Duration
+		expected waveletv1alpha1.WaveletBenchmarkResults
+	}{
+		{
+			name:     "no workers",
+			workers:  nil,
+			elapsed:  time.Minute,
+			expected: waveletv1alpha1.WaveletBenchmarkResults{TPS: "0.00", Latency: latency(0, 0, 0, 0)},
+		},
+		{
+			name:     "no elapsed time",
+			workers:  []workerResult{{Worker: "a", Samples: 1, Accepted: 100, latencies: durations(5)}},
+			elapsed:  0,
+			expected: waveletv1alpha1.WaveletBenchmarkResults{Workers: 1, Accepted: 100, TPS: "0.00", Latency: latency(5, 5, 5, 5)},
+		},
+		{
+			name: "latencies combined across workers",
+			workers: []workerResult{
+				{Worker: "a", Samples: 2, Accepted: 200, Errors: 1, latencies: durations(10, 20)},
+				{Worker: "b", Samples: 1, Accepted: 100, Errors: 2, latencies: durations(30)},
+				{Worker: "c"},
+			},
+			elapsed:  30 * time.Second,
+			expected: waveletv1alpha1.WaveletBenchmarkResults{Workers: 3, Accepted: 300, Errors: 3, TPS: "10.00", Latency: latency(20, 30, 30, 30)},
+		},
+		{
+			name:     "tps rounded to two decimal places",
+			workers:  []workerResult{{Worker: "a", Samples: 1, Accepted: 10}},
+			elapsed:  3 * time.Second,
+			expected: waveletv1alpha1.WaveletBenchmarkResults{Workers: 1, Accepted: 10, TPS: "3.33", Latency: latency(0, 0, 0, 0)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := aggregateResults(test.workers, test.elapsed); actual != test.expected {
+				t.Errorf("expected %+v, got %+v", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseWorkerLog(t *testing.T) {
+	tests := []struct {
+		name     string
+		logs     []string
+		samples  int
+		accepted uint64
+		errors   uint64
+		latency  waveletv1alpha1.WaveletBenchmarkLatency
+	}{
+		{
+			name:    "empty log",
+			logs:    []string{""},
+			latency: latency(0, 0, 0, 0),
+		},
+		{
+			name: "samples",
+			logs: []string{
+				`{"event":"benchmark","num_accepted":10,"num_errors":1,"latencies_ms":[10,20]}` + "\n" +
+					`{"event":"benchmark","num_accepted":5,"num_errors":0,"latencies_ms":[30]}`,
+			},
+			samples:  2,
+			accepted: 15,
+			errors:   1,
+			latency:  latency(20, 30, 30, 30),
+		},
+		{
+			name: "malformed and unrelated lines",
+			logs: []string{
+				"Starting benchmark.\n" +
+					`{"event":"benchmark","num_accepted":` + "\n" +
+					`{"event":"connected","num_accepted":100}` + "\n" +
+					`{"num_accepted":100}` + "\n" +
+					`["benchmark"]` + "\n" +
+					`{"event":"benchmark","num_accepted":"many"}` + "\n" +
+					`{"event":"benchmark","num_accepted":3,"num_errors":2}` + "\n" +
+					"\n",
+			},
+			samples:  1,
+			accepted: 3,
+			errors:   2,
+			latency:  latency(0, 0, 0, 0),
+		},
+		{
+			name: "only unrelated lines",
+			logs: []string{
+				"Starting benchmark.\n" + `{"level":"info","message":"Connected."}`,
+			},
+			latency: latency(0, 0, 0, 0),
+		},
+		{
+			name: "previous and latest run",
+			logs: []string{
+				`{"event":"benchmark","num_accepted":4,"latencies_ms":[1]}`,
+				`{"event":"benchmark","num_accepted":6,"num_errors":1,"latencies_ms":[2]}`,
+			},
+			samples:  2,
+			accepted: 10,
+			errors:   1,
+			latency:  latency(1, 2, 2, 2),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var logs []io.Reader
+
+			for _, log := range test.logs {
+				logs = append(logs, strings.NewReader(log))
+			}
+
+			result, err := parseWorkerLog("worker", logs...)
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.Worker != "worker" {
+				t.Errorf("expected worker %q, got %q", "worker", result.Worker)
+			}
+
+			if result.Samples != test.samples || result.Accepted != test.accepted || result.Errors != test.errors {
+				t.Errorf("expected %d samples with %d accepted and %d errors, got %d samples with %d accepted and %d errors", test.samples, test.accepted, test.errors, result.Samples, result.Accepted, result.Errors)
+			}
+
+			if result.Latency != test.latency {
+				t.Errorf("expected latency %+v, got %+v", test.latency, result.Latency)
+			}
+		})
+	}
+}
+
+func TestParseWorkerLogLineTooLong(t *testing.T) {
+	if _, err := parseWorkerLog("worker", strings.NewReader(strings.Repeat("x", maxLogLineSize+1))); err == nil {
+		t.Error("expected an error for a line longer than the maximum line size")
+	}
+}
+
+func TestGetSilentWorkers(t *testing.T) {
+	silent := getSilentWorkers([]workerResult{{Worker: "a", Samples: 1}, {Worker: "b"}, {Worker: "c", Samples: 3}, {Worker: "d"}})
+
+	if strings.Join(silent, ",") != "b,d" {
+		t.Errorf("expected workers b and d to be silent, got %v", silent)
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package waveletbenchmark
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-logr/logr"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// DefaultHistoryLimit is the number of finished runs kept around for a scheduled benchmark should it leave it unset.
+const DefaultHistoryLimit = 10
+
+// DefaultRegressionThresholdPercent is the regression threshold of a scheduled benchmark should it leave it unset.
+const DefaultRegressionThresholdPercent = 10
+
+// maxMissedSchedules bounds how many missed schedules are walked through to find the latest one, should the operator
+// have been down for a while.
+const maxMissedSchedules = 1000
+
+func getBenchmarkHistoryLimit(benchmark *waveletv1alpha1.WaveletBenchmark) int {
+	if benchmark.Spec.HistoryLimit > 0 {
+		return int(benchmark.Spec.HistoryLimit)
+	}
+
+	return DefaultHistoryLimit
+}
+
+func getBenchmarkRegressionThreshold(benchmark *waveletv1alpha1.WaveletBenchmark) int32 {
+	if benchmark.Spec.Regression != nil && benchmark.Spec.Regression.ThresholdPercent > 0 {
+		return benchmark.Spec.Regression.ThresholdPercent
+	}
+
+	return DefaultRegressionThresholdPercent
+}
+
+// getBenchmarkRun returns the run of a scheduled benchmark that is due at a given time. Runs are named after the
+// minute they are due at, such that a run is never launched twice for the same schedule.
+func getBenchmarkRun(benchmark *waveletv1alpha1.WaveletBenchmark, scheduled time.Time) *waveletv1alpha1.WaveletBenchmark {
+	spec := *benchmark.Spec.DeepCopy()
+
+	spec.Schedule = ""
+	spec.HistoryLimit = 0
+	spec.Regression = nil
+	spec.Stop = false
+
+	return &waveletv1alpha1.WaveletBenchmark{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", benchmark.Name, scheduled.Unix()/60),
+			Namespace: benchmark.Namespace,
+			Labels:    labelsForBenchmark(benchmark, "run"),
+		},
+		Spec: spec,
+	}
+}
+
+func getBenchmarkRunStatus(run *waveletv1alpha1.WaveletBenchmark) waveletv1alpha1.WaveletBenchmarkRunStatus {
+	return waveletv1alpha1.WaveletBenchmarkRunStatus{
+		Name:           run.Name,
+		Phase:          run.Status.Phase,
+		StartTime:      run.Status.StartTime,
+		CompletionTime: run.Status.CompletionTime,
+		Results:        run.Status.Results,
+	}
+}
+
+// detectRegression compares the results of a run against those of a baseline, and describes how the run performed
+// worse than the baseline should its TPS drop or its p99 latency rise beyond a threshold given as a percentage.
+func detectRegression(baseline, run *waveletv1alpha1.WaveletBenchmarkResults, thresholdPercent int32) (bool, string) {
+	threshold := float64(thresholdPercent) / 100
+
+	var problems []string
+
+	baselineTPS, err1 := strconv.ParseFloat(baseline.TPS, 64)
+	runTPS, err2 := strconv.ParseFloat(run.TPS, 64)
+
+	if err1 == nil && err2 == nil && baselineTPS > 0 && runTPS < baselineTPS*(1-threshold) {
+		problems = append(problems, fmt.Sprintf("TPS dropped from %s to %s", baseline.TPS, run.TPS))
+	}
+
+	baselineP99, runP99 := baseline.Latency.P99.Duration, run.Latency.P99.Duration
+
+	if baselineP99 > 0 && float64(runP99) > float64(baselineP99)*(1+threshold) {
+		problems = append(problems, fmt.Sprintf("p99 latency rose from %s to %s", baselineP99, runP99))
+	}
+
+	if len(problems) == 0 {
+		return false, ""
+	}
+
+	return true, fmt.Sprintf("%s, beyond a threshold of %d%%.", strings.Join(problems, " and "), thresholdPercent)
+}
+
+// listRuns returns all runs of a scheduled benchmark ordered from oldest to newest.
+func (r *ReconcileWaveletBenchmark) listRuns(benchmark *waveletv1alpha1.WaveletBenchmark) ([]waveletv1alpha1.WaveletBenchmark, error) {
+	list := new(waveletv1alpha1.WaveletBenchmarkList)
+
+	opts := &client.ListOptions{Namespace: benchmark.Namespace, LabelSelector: labels.SelectorFromSet(labelsForBenchmark(benchmark, "run"))}
+
+	if err := r.client.List(context.TODO(), opts, list); err != nil {
+		return nil, err
+	}
+
+	runs := list.Items
+
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].CreationTimestamp.Equal(&runs[j].CreationTimestamp) {
+			return runs[i].CreationTimestamp.Before(&runs[j].CreationTimestamp)
+		}
+
+		return runs[i].Name < runs[j].Name
+	})
+
+	return runs, nil
+}
+
+// getBaseline returns the run that runs of a scheduled benchmark are compared against. An explicitly configured
+// baseline is read from the report ConfigMap it names, and the first run to complete is the baseline otherwise.
+func (r *ReconcileWaveletBenchmark) getBaseline(benchmark *waveletv1alpha1.WaveletBenchmark, runs []waveletv1alpha1.WaveletBenchmark) (*waveletv1alpha1.WaveletBenchmarkRunStatus, error) {
+	current := benchmark.Status.Baseline
+
+	if benchmark.Spec.Regression != nil && len(benchmark.Spec.Regression.Baseline) > 0 {
+		name := benchmark.Spec.Regression.Baseline
+
+		configMap := new(corev1.ConfigMap)
+
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: benchmark.Namespace, Name: name}, configMap); err != nil {
+			// The baseline kept in status remains usable should its report have been pruned in the meantime.
+			if errors.IsNotFound(err) && current != nil && current.Results != nil && current.Results.Report == name {
+				return current, nil
+			}
+
+			return nil, err
+		}
+
+		var report benchmarkReport
+
+		if err := json.Unmarshal([]byte(configMap.Data[ReportKey]), &report); err != nil {
+			return nil, fmt.Errorf("failed to parse baseline report %q: %v", name, err)
+		}
+
+		report.Results.Report = name
+
+		return &waveletv1alpha1.WaveletBenchmarkRunStatus{
+			Name:           report.Benchmark,
+			Phase:          report.Phase,
+			StartTime:      report.StartTime,
+			CompletionTime: report.CompletionTime,
+			Results:        &report.Results,
+		}, nil
+	}
+
+	// The baseline is stored once chosen, such that it outlives the run it was taken from.
+	if current != nil {
+		return current, nil
+	}
+
+	for _, run := range runs {
+		if run.Status.Phase == waveletv1alpha1.WaveletBenchmarkCompleted && run.Status.Results != nil {
+			baseline := getBenchmarkRunStatus(&run)
+			return &baseline, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// trackRuns records the runs of a scheduled benchmark in its status, flagging runs that regressed against the
+// baseline should regression detection be configured. Finished runs beyond the history limit of the benchmark are
+// pruned alongside their reports, except for the baseline run.
+func (r *ReconcileWaveletBenchmark) trackRuns(logger logr.Logger, benchmark *waveletv1alpha1.WaveletBenchmark, runs []waveletv1alpha1.WaveletBenchmark) error {
+	var baseline *waveletv1alpha1.WaveletBenchmarkRunStatus
+
+	if benchmark.Spec.Regression != nil {
+		var err error
+
+		if baseline, err = r.getBaseline(benchmark, runs); err != nil {
+			logger.Error(err, "Failed to get the baseline of the benchmark.")
+			return err
+		}
+	}
+
+	var finished []int
+
+	for i := range runs {
+		if isBenchmarkFinished(&runs[i]) && (baseline == nil || runs[i].Name != baseline.Name) {
+			finished = append(finished, i)
+		}
+	}
+
+	pruned := make(map[string]struct{})
+
+	for len(finished) > getBenchmarkHistoryLimit(benchmark) {
+		run := &runs[finished[0]]
+		finished = finished[1:]
+
+		if err := r.client.Delete(context.TODO(), run, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to prune run.", "run", run.Name)
+			return err
+		}
+
+		pruned[run.Name] = struct{}{}
+
+		logger.Info("Pruned run.", "run", run.Name)
+	}
+
+	previous := make(map[string]waveletv1alpha1.WaveletBenchmarkRunStatus, len(benchmark.Status.Runs))
+
+	for _, status := range benchmark.Status.Runs {
+		previous[status.Name] = status
+	}
+
+	history := make([]waveletv1alpha1.WaveletBenchmarkRunStatus, 0, len(runs))
+	active := ""
+
+	for i := range runs {
+		run := &runs[i]
+
+		if _, exists := pruned[run.Name]; exists {
+			continue
+		}
+
+		if !isBenchmarkFinished(run) {
+			active = run.Name
+		}
+
+		status := getBenchmarkRunStatus(run)
+
+		if baseline != nil && baseline.Results != nil && run.Name != baseline.Name && run.Status.Phase == waveletv1alpha1.WaveletBenchmarkCompleted && run.Status.Results != nil {
+			status.Regressed, status.Regression = detectRegression(baseline.Results, run.Status.Results, getBenchmarkRegressionThreshold(benchmark))
+
+			if status.Regressed && !previous[run.Name].Regressed {
+				r.recorder.Eventf(benchmark, corev1.EventTypeWarning, "Regression", "Run %q regressed against baseline %q: %s", run.Name, baseline.Name, status.Regression)
+			}
+		}
+
+		history = append(history, status)
+	}
+
+	benchmark.Status.Baseline = baseline
+	benchmark.Status.Runs = history
+	benchmark.Status.Active = active
+
+	return nil
+}
+
+// reconcileSchedule launches runs of a scheduled benchmark as they become due, and keeps track of them. Stopping a
+// scheduled benchmark stops the run in progress and launches no further runs.
+func (r *ReconcileWaveletBenchmark) reconcileSchedule(logger logr.Logger, benchmark *waveletv1alpha1.WaveletBenchmark) (reconcile.Result, error) {
+	schedule, err := parseCronSchedule(benchmark.Spec.Schedule)
+
+	if err != nil {
+		r.finish(benchmark, waveletv1alpha1.WaveletBenchmarkFailed, "InvalidSchedule", fmt.Sprintf("Schedule %q is invalid: %v", benchmark.Spec.Schedule, err))
+		return reconcile.Result{}, nil
+	}
+
+	runs, err := r.listRuns(benchmark)
+
+	if err != nil {
+		logger.Error(err, "Failed to list all runs of the benchmark.")
+		return reconcile.Result{}, err
+	}
+
+	if benchmark.Spec.Stop {
+		for i := range runs {
+			run := &runs[i]
+
+			if isBenchmarkFinished(run) || run.Spec.Stop {
+				continue
+			}
+
+			run.Spec.Stop = true
+
+			if err := r.client.Update(context.TODO(), run); err != nil {
+				logger.Error(err, "Failed to stop run.", "run", run.Name)
+				return reconcile.Result{}, err
+			}
+
+			logger.Info("Stopped run.", "run", run.Name)
+		}
+
+		r.finish(benchmark, waveletv1alpha1.WaveletBenchmarkStopped, "Stopped", "The benchmark was stopped, and launches no further runs.")
+
+		return reconcile.Result{}, nil
+	}
+
+	if err := r.trackRuns(logger, benchmark, runs); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	now := time.Now()
+
+	last := benchmark.CreationTimestamp.Time
+
+	if benchmark.Status.LastScheduleTime != nil {
+		last = benchmark.Status.LastScheduleTime.Time
+	}
+
+	// Should runs have been missed (i.e. as the operator was down), only the latest of them is launched.
+	var due time.Time
+
+	for i, t := 0, schedule.next(last); i < maxMissedSchedules && !t.IsZero() && !t.After(now); i, t = i+1, schedule.next(t) {
+		due = t
+	}
+
+	benchmark.Status.Phase = waveletv1alpha1.WaveletBenchmarkScheduled
+
+	if !due.IsZero() {
+		scheduled := metav1.NewTime(due)
+		benchmark.Status.LastScheduleTime = &scheduled
+
+		if len(benchmark.Status.Active) > 0 {
+			logger.Info("Skipping run as the previous run is still in progress.", "active", benchmark.Status.Active, "scheduled", due)
+			r.recorder.Eventf(benchmark, corev1.EventTypeNormal, "RunSkipped", "Skipped the run due at %s as run %q is still in progress.", due.Format(time.RFC3339), benchmark.Status.Active)
+		} else {
+			run := getBenchmarkRun(benchmark, due)
+
+			if err := controllerutil.SetControllerReference(benchmark, run, r.scheme); err != nil {
+				return reconcile.Result{}, err
+			}
+
+			if err := r.client.Create(context.TODO(), run); err != nil && !errors.IsAlreadyExists(err) {
+				logger.Error(err, "Failed to launch run.", "run", run.Name)
+				return reconcile.Result{}, err
+			}
+
+			benchmark.Status.Active = run.Name
+
+			logger.Info("Launched run.", "run", run.Name)
+			r.recorder.Eventf(benchmark, corev1.EventTypeNormal, "RunLaunched", "Launched run %q due at %s.", run.Name, due.Format(time.RFC3339))
+		}
+	}
+
+	next := schedule.next(now)
+
+	if next.IsZero() {
+		benchmark.Status.Reason, benchmark.Status.Message = "NoUpcomingRuns", fmt.Sprintf("Schedule %q matches no time in the foreseeable future.", benchmark.Spec.Schedule)
+		return reconcile.Result{}, nil
+	}
+
+	benchmark.Status.Reason, benchmark.Status.Message = "Scheduled", fmt.Sprintf("The next run is due at %s.", next.Format(time.RFC3339))
+
+	return reconcile.Result{RequeueAfter: time.Until(next)}, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package waveletbenchmark
+
+import (
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"testing"
+)
+
+func TestDetectRegression(t *testing.T) {
+	results := func(tps string, p99 int) *waveletv1alpha1.WaveletBenchmarkResults {
+		return &waveletv1alpha1.WaveletBenchmarkResults{TPS: tps, Latency: latency(p99/2, p99/2, p99, p99)}
+	}
+
+	tests := []struct {
+		name       string
+		baseline   *waveletv1alpha1.WaveletBenchmarkResults
+		run        *waveletv1alpha1.WaveletBenchmarkResults
+		threshold  int32
+		regression bool
+		message    string
+	}{
+		{name: "unchanged", baseline: results("100", 100), run: results("100", 100), threshold: 10},
+		{name: "improved", baseline: results("100", 100), run: results("150", 50), threshold: 10},
+		{name: "tps within threshold", baseline: results("100", 100), run: results("91", 100), threshold: 10},
+		{name: "tps at threshold", baseline: results("100", 100), run: results("90", 100), threshold: 10},
+		{
+			name: "tps beyond threshold", baseline: results("100", 100), run: results("89.9", 100), threshold: 10,
+			regression: true, message: "TPS dropped from 100 to 89.9, beyond a threshold of 10%.",
+		},
+		{name: "p99 within threshold", baseline: results("100", 100), run: results("100", 109), threshold: 10},
+		{name: "p99 at threshold", baseline: results("100", 100), run: results("100", 110), threshold: 10},
+		{
+			name: "p99 beyond threshold", baseline: results("100", 100), run: results("100", 111), threshold: 10,
+			regression: true, message: "p99 latency rose from 100ms to 111ms, beyond a threshold of 10%.",
+		},
+		{
+			name: "both beyond threshold", baseline: results("100", 100), run: results("50", 200), threshold: 10,
+			regression: true, message: "TPS dropped from 100 to 50 and p99 latency rose from 100ms to 200ms, beyond a threshold of 10%.",
+		},
+		{
+			name: "zero threshold", baseline: results("100", 100), run: results("99.9", 100), threshold: 0,
+			regression: true, message: "TPS dropped from 100 to 99.9, beyond a threshold of 0%.",
+		},
+		{name: "wide threshold", baseline: results("100", 100), run: results("60", 190), threshold: 100},
+		{name: "baseline without tps", baseline: results("0", 100), run: results("0", 100), threshold: 10},
+		{name: "unparseable tps", baseline: results("", 100), run: results("1", 100), threshold: 10},
+		{
+			name: "baseline without latency", baseline: results("100", 0), run: results("10", 100), threshold: 10,
+			regression: true, message: "TPS dropped from 100 to 10, beyond a threshold of 10%.",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			regression, message := detectRegression(test.baseline, test.run, test.threshold)
+
+			if regression != test.regression {
+				t.Errorf("expected regression to be %t, got %t (%q)", test.regression, regression, message)
+			}
+
+			if message != test.message {
+				t.Errorf("expected message %q, got %q", test.message, message)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package waveletbenchmark
+
+import (
+	"fmt"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"github.com/perlin-network/wavelet-operator/pkg/controller/wavelet"
+	"k8s.io/apimachinery/pkg/labels"
+	"net"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Benchmark parameters are handed to the benchmark client run by each worker as flags following the -host and -wallet
+// flags of `benchmark remote` (see wavelet.GetWaveletBenchmarkPodSpec). The benchmark image of a WaveletBenchmark
+// must support them: an image that does not exits on the unknown flag, which fails the benchmark (see
+// getWorkerFailure) rather than letting it run with its parameters silently ignored.
+//
+// Results do not depend on the output of the benchmark client. The number of transactions accepted by the cluster is
+// measured through the HTTP API of its nodes on port 9000, from how far the nonces of the wallets of workers advanced
+// over the course of the benchmark (see measureLedger). Clients may in addition log samples as JSON objects of the
+// form {"event":"benchmark","num_accepted":...,"num_errors":...,"latencies_ms":[...]} on their own line, from which
+// errors and latencies are reported (see parseWorkerLog).
+const (
+	FlagBenchmarkTPS          = "-tps"
+	FlagBenchmarkTransactions = "-transactions"
+)
+
+// labelsForBenchmark returns the labels of resources of a given role belonging to a benchmark. Worker pods
+// deliberately carry no app label, such that they are never mistaken for the pods of a cluster.
+func labelsForBenchmark(benchmark *waveletv1alpha1.WaveletBenchmark, role string) labels.Set {
+	return labels.Set{"benchmark": benchmark.Name, "role": role}
+}
+
+func getBenchmarkWorkerPodPrefix(benchmark *waveletv1alpha1.WaveletBenchmark) string {
+	return fmt.Sprintf("%s-worker", benchmark.Name)
+}
+
+// getBenchmarkWorkerTPS returns the share of the target TPS of a benchmark submitted by the worker with a given
+// ordinal. The remainder of splitting the target evenly across workers is spread over the first few workers.
+func getBenchmarkWorkerTPS(benchmark *waveletv1alpha1.WaveletBenchmark, idx uint) uint32 {
+	workers := uint32(benchmark.Spec.Workers)
+
+	if workers == 0 {
+		return 0
+	}
+
+	tps := benchmark.Spec.TargetTPS / workers
+
+	if uint32(idx) < benchmark.Spec.TargetTPS%workers {
+		tps++
+	}
+
+	return tps
+}
+
+// getBenchmarkTransactions renders the transaction mix of a benchmark as a comma-separated list of type=weight pairs.
+func getBenchmarkTransactions(benchmark *waveletv1alpha1.WaveletBenchmark) string {
+	if len(benchmark.Spec.Transactions) == 0 {
+		return fmt.Sprintf("%s=1", waveletv1alpha1.WaveletBenchmarkTransfer)
+	}
+
+	mix := make([]string, 0, len(benchmark.Spec.Transactions))
+
+	for _, tx := range benchmark.Spec.Transactions {
+		mix = append(mix, fmt.Sprintf("%s=%d", tx.Type, tx.Weight))
+	}
+
+	return strings.Join(mix, ",")
+}
+
+// getBenchmarkWorkerPod returns the worker pod with a given ordinal, which runs a benchmark client against the node
+// with a given ordinal signing transactions with the wallet at a given path.
+func getBenchmarkWorkerPod(benchmark *waveletv1alpha1.WaveletBenchmark, cluster *waveletv1alpha1.Wavelet, node uint, wallet string, idx uint) *corev1.Pod {
+	host := net.JoinHostPort(wavelet.GetWaveletNodeHost(cluster, node), "9000")
+
+	spec := wavelet.GetWaveletBenchmarkPodSpec(cluster, host, wallet)
+
+	container := &spec.Containers[0]
+
+	if len(benchmark.Spec.Image) > 0 {
+		container.Image = benchmark.Spec.Image
+	}
+
+	container.Command = append(container.Command,
+		FlagBenchmarkTPS, strconv.FormatUint(uint64(getBenchmarkWorkerTPS(benchmark, idx)), 10),
+		FlagBenchmarkTransactions, getBenchmarkTransactions(benchmark),
+	)
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-%d", getBenchmarkWorkerPodPrefix(benchmark), idx),
+			Namespace:   benchmark.Namespace,
+			Labels:      labelsForBenchmark(benchmark, "worker"),
+			Annotations: map[string]string{wavelet.AnnotationSpecHash: wavelet.HashObject(spec)},
+		},
+		Spec: spec,
+	}
+}
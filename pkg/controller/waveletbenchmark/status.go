@@ -0,0 +1,106 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package waveletbenchmark
+
+import (
+	"context"
+	"fmt"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"github.com/perlin-network/wavelet-operator/pkg/controller/wavelet"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// isBenchmarkFinished reports whether a benchmark has moved into a terminal phase.
+func isBenchmarkFinished(benchmark *waveletv1alpha1.WaveletBenchmark) bool {
+	switch benchmark.Status.Phase {
+	case waveletv1alpha1.WaveletBenchmarkCompleted, waveletv1alpha1.WaveletBenchmarkStopped, waveletv1alpha1.WaveletBenchmarkFailed:
+		return true
+	}
+
+	return false
+}
+
+// getWorkerFailure describes the first worker container found to have exited with a non-zero code, such as a
+// benchmark image rejecting the parameters it is passed. It reports false should no worker have failed.
+func getWorkerFailure(workers []corev1.Pod) (string, bool) {
+	for _, worker := range workers {
+		for _, status := range worker.Status.ContainerStatuses {
+			for _, terminated := range []*corev1.ContainerStateTerminated{status.State.Terminated, status.LastTerminationState.Terminated} {
+				if terminated == nil || terminated.ExitCode == 0 {
+					continue
+				}
+
+				message := terminated.Message
+
+				if len(message) == 0 {
+					message = terminated.Reason
+				}
+
+				return fmt.Sprintf("Worker %s exited with code %d: %s", worker.Name, terminated.ExitCode, message), true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func setPending(benchmark *waveletv1alpha1.WaveletBenchmark, reason, message string) {
+	benchmark.Status.Phase = waveletv1alpha1.WaveletBenchmarkPending
+	benchmark.Status.Reason = reason
+	benchmark.Status.Message = message
+}
+
+// updateStatus observes all worker pods of a benchmark, and writes a summary of them alongside the phase the last
+// reconciliation pass moved the benchmark into should the status differ from original.
+func (r *ReconcileWaveletBenchmark) updateStatus(benchmark *waveletv1alpha1.WaveletBenchmark, original *waveletv1alpha1.WaveletBenchmarkStatus, reconcileErr error) error {
+	workers, err := r.listWorkers(benchmark)
+
+	if err != nil {
+		return err
+	}
+
+	status := &benchmark.Status
+
+	if status.Phase == "" {
+		status.Phase = waveletv1alpha1.WaveletBenchmarkPending
+	}
+
+	status.ObservedGeneration = benchmark.Generation
+	status.Workers = int32(len(workers))
+	status.ReadyWorkers = 0
+
+	for _, worker := range workers {
+		if wavelet.IsPodReady(worker) {
+			status.ReadyWorkers++
+		}
+	}
+
+	if reconcileErr != nil && !isBenchmarkFinished(benchmark) {
+		status.Reason, status.Message = "ReconcileFailed", reconcileErr.Error()
+	}
+
+	if reflect.DeepEqual(status, original) {
+		return nil
+	}
+
+	return r.client.Status().Update(context.TODO(), benchmark)
+}
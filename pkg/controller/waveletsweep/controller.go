@@ -0,0 +1,414 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package waveletsweep
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-logr/logr"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+var log = logf.Log.WithName("waveletsweep.controller")
+
+// Add creates a new WaveletSweep Controller and adds it to the Manager. The Manager will set fields on the
+// Controller and Start it when the Manager is Started.
+func Add(mgr manager.Manager) error {
+	return add(mgr, newReconciler(mgr))
+}
+
+// newReconciler returns a new reconcile.Reconciler
+func newReconciler(mgr manager.Manager) *ReconcileWaveletSweep {
+	return &ReconcileWaveletSweep{
+		client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		recorder: mgr.GetRecorder("waveletsweep-controller"),
+	}
+}
+
+// add adds a new Controller to mgr with r as the reconcile.Reconciler
+func add(mgr manager.Manager, r *ReconcileWaveletSweep) error {
+	c, err := controller.New("waveletsweep-controller", mgr, controller.Options{Reconciler: r})
+
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: new(waveletv1alpha1.WaveletSweep)}, new(handler.EnqueueRequestForObject))
+
+	if err != nil {
+		return err
+	}
+
+	err = c.Watch(&source.Kind{Type: new(waveletv1alpha1.WaveletBenchmark)}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    new(waveletv1alpha1.WaveletSweep),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// Sweeps wait on the cluster they sweep to converge at each step, so changes to the cluster are mapped back to
+	// every sweep referencing it.
+	err = c.Watch(&source.Kind{Type: new(waveletv1alpha1.Wavelet)}, &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.mapClusterToSweeps)})
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *ReconcileWaveletSweep) mapClusterToSweeps(obj handler.MapObject) []reconcile.Request {
+	list := new(waveletv1alpha1.WaveletSweepList)
+
+	if err := r.client.List(context.TODO(), &client.ListOptions{Namespace: obj.Meta.GetNamespace()}, list); err != nil {
+		log.Error(err, "Failed to list sweeps referencing a cluster.", "namespace", obj.Meta.GetNamespace(), "cluster", obj.Meta.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+
+	for _, sweep := range list.Items {
+		if sweep.Spec.Cluster == obj.Meta.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: sweep.Namespace, Name: sweep.Name}})
+		}
+	}
+
+	return requests
+}
+
+var _ reconcile.Reconciler = &ReconcileWaveletSweep{}
+
+type ReconcileWaveletSweep struct {
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
+}
+
+func (r *ReconcileWaveletSweep) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	logger := log.WithValues("request.namespace", request.Namespace, "request.name", request.Name)
+
+	sweep := new(waveletv1alpha1.WaveletSweep)
+
+	if err := r.client.Get(context.TODO(), request.NamespacedName, sweep); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	original := sweep.Status.DeepCopy()
+
+	result, err := r.reconcile(logger, sweep)
+
+	sweep.Status.ObservedGeneration = sweep.Generation
+
+	if err != nil && !isSweepFinished(sweep) {
+		sweep.Status.Reason, sweep.Status.Message = "ReconcileFailed", err.Error()
+	}
+
+	if !reflect.DeepEqual(&sweep.Status, original) {
+		if err := r.client.Status().Update(context.TODO(), sweep); err != nil {
+			logger.Error(err, "Failed to update the status of the sweep.")
+			return reconcile.Result{}, err
+		}
+	}
+
+	return result, err
+}
+
+// isSweepFinished reports whether a sweep has moved into a terminal phase.
+func isSweepFinished(sweep *waveletv1alpha1.WaveletSweep) bool {
+	switch sweep.Status.Phase {
+	case waveletv1alpha1.WaveletSweepCompleted, waveletv1alpha1.WaveletSweepStopped, waveletv1alpha1.WaveletSweepFailed:
+		return true
+	}
+
+	return false
+}
+
+func setPhase(sweep *waveletv1alpha1.WaveletSweep, phase waveletv1alpha1.WaveletSweepPhase, reason, message string) {
+	sweep.Status.Phase = phase
+	sweep.Status.Reason = reason
+	sweep.Status.Message = message
+}
+
+// finish moves a sweep into a terminal phase.
+func (r *ReconcileWaveletSweep) finish(sweep *waveletv1alpha1.WaveletSweep, phase waveletv1alpha1.WaveletSweepPhase, reason, message string) {
+	now := metav1.Now()
+
+	setPhase(sweep, phase, reason, message)
+	sweep.Status.CompletionTime = &now
+
+	eventType := corev1.EventTypeNormal
+
+	if phase == waveletv1alpha1.WaveletSweepFailed {
+		eventType = corev1.EventTypeWarning
+	}
+
+	r.recorder.Event(sweep, eventType, reason, message)
+}
+
+// stopBenchmark stops the benchmark run at a given step of a sweep, should it still be running.
+func (r *ReconcileWaveletSweep) stopBenchmark(logger logr.Logger, sweep *waveletv1alpha1.WaveletSweep, idx int32) error {
+	benchmark := new(waveletv1alpha1.WaveletBenchmark)
+
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: sweep.Namespace, Name: getSweepBenchmarkName(sweep, idx)}, benchmark); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	if benchmark.Spec.Stop || benchmark.Status.CompletionTime != nil {
+		return nil
+	}
+
+	benchmark.Spec.Stop = true
+
+	if err := r.client.Update(context.TODO(), benchmark); err != nil {
+		logger.Error(err, "Failed to stop benchmark.", "benchmark", benchmark.Name)
+		return err
+	}
+
+	logger.Info("Stopped benchmark.", "benchmark", benchmark.Name)
+
+	return nil
+}
+
+// writeTable publishes the results of every step of a sweep benchmarked so far as CSV in a ConfigMap owned by the
+// sweep.
+func (r *ReconcileWaveletSweep) writeTable(logger logr.Logger, sweep *waveletv1alpha1.WaveletSweep) error {
+	table, err := getSweepTable(sweep)
+
+	if err != nil {
+		return err
+	}
+
+	configMap := getSweepTableConfigMap(sweep, table)
+
+	if err := controllerutil.SetControllerReference(sweep, configMap, r.scheme); err != nil {
+		return err
+	}
+
+	if err := r.client.Create(context.TODO(), configMap); err != nil {
+		if !errors.IsAlreadyExists(err) {
+			logger.Error(err, "Failed to create the result table of the sweep.")
+			return err
+		}
+
+		if err := r.client.Update(context.TODO(), configMap); err != nil {
+			logger.Error(err, "Failed to update the result table of the sweep.")
+			return err
+		}
+	}
+
+	sweep.Status.Table = configMap.Name
+
+	return nil
+}
+
+func (r *ReconcileWaveletSweep) reconcile(logger logr.Logger, sweep *waveletv1alpha1.WaveletSweep) (reconcile.Result, error) {
+	if isSweepFinished(sweep) {
+		return reconcile.Result{}, nil
+	}
+
+	if sweep.Status.StartTime == nil {
+		now := metav1.Now()
+		sweep.Status.StartTime = &now
+	}
+
+	idx := sweep.Status.Step
+
+	if sweep.Spec.Stop {
+		if err := r.stopBenchmark(logger, sweep, idx); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		r.finish(sweep, waveletv1alpha1.WaveletSweepStopped, "Stopped", fmt.Sprintf("The sweep was stopped at step %d of %d.", idx+1, sweep.Status.Steps))
+
+		return reconcile.Result{}, nil
+	}
+
+	cluster := new(waveletv1alpha1.Wavelet)
+
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: sweep.Namespace, Name: sweep.Spec.Cluster}, cluster); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	} else if errors.IsNotFound(err) || cluster.GetDeletionTimestamp() != nil {
+		if err := r.stopBenchmark(logger, sweep, idx); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		r.finish(sweep, waveletv1alpha1.WaveletSweepFailed, "ClusterNotFound", fmt.Sprintf("Cluster %q does not exist.", sweep.Spec.Cluster))
+
+		return reconcile.Result{}, nil
+	}
+
+	steps := getSweepSteps(sweep, cluster)
+
+	sweep.Status.Steps = int32(len(steps))
+
+	if int(idx) >= len(steps) {
+		if err := r.writeTable(logger, sweep); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		r.finish(sweep, waveletv1alpha1.WaveletSweepCompleted, "Completed", fmt.Sprintf("Benchmarked all %d steps of the sweep. See ConfigMap %q.", len(steps), sweep.Status.Table))
+
+		return reconcile.Result{}, nil
+	}
+
+	step := steps[idx]
+
+	benchmark := new(waveletv1alpha1.WaveletBenchmark)
+
+	err := r.client.Get(context.TODO(), types.NamespacedName{Namespace: sweep.Namespace, Name: getSweepBenchmarkName(sweep, idx)}, benchmark)
+
+	if err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+
+	// The cluster is only reconfigured and waited on until the benchmark of the step is launched, such that the
+	// benchmark is not disrupted by the cluster changing underneath it.
+	if errors.IsNotFound(err) {
+		return r.converge(logger, sweep, cluster, idx, step)
+	}
+
+	switch {
+	case benchmark.Status.CompletionTime == nil:
+		setPhase(sweep, waveletv1alpha1.WaveletSweepBenchmarking, "Benchmarking", fmt.Sprintf("Benchmarking step %d of %d with size %d and snowball_k %d.", idx+1, len(steps), step.size, step.snowballK))
+		return reconcile.Result{}, nil
+	case benchmark.Status.StartTime != nil && benchmark.Status.Results == nil && benchmark.Status.Phase != waveletv1alpha1.WaveletBenchmarkFailed:
+		setPhase(sweep, waveletv1alpha1.WaveletSweepBenchmarking, "CollectingResults", fmt.Sprintf("Waiting for the results of step %d of %d to be collected.", idx+1, len(steps)))
+		return reconcile.Result{}, nil
+	}
+
+	sweep.Status.Results = append(sweep.Status.Results, waveletv1alpha1.WaveletSweepResult{
+		Step:      idx,
+		Size:      step.size,
+		SnowballK: step.snowballK,
+		Benchmark: benchmark.Name,
+		Phase:     benchmark.Status.Phase,
+		Results:   benchmark.Status.Results,
+	})
+
+	sweep.Status.Step++
+	sweep.Status.ConvergedTime = nil
+
+	if err := r.writeTable(logger, sweep); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	logger.Info("Benchmarked step of the sweep.", "step", idx, "size", step.size, "snowball_k", step.snowballK, "benchmark_phase", benchmark.Status.Phase)
+
+	r.recorder.Eventf(sweep, corev1.EventTypeNormal, "StepBenchmarked", "Benchmarked step %d of %d with size %d and snowball_k %d.", idx+1, len(steps), step.size, step.snowballK)
+
+	return reconcile.Result{Requeue: true}, nil
+}
+
+// converge reconfigures a cluster to a step of a sweep, waits for it to converge and settle, and launches the
+// benchmark of the step once done.
+func (r *ReconcileWaveletSweep) converge(logger logr.Logger, sweep *waveletv1alpha1.WaveletSweep, cluster *waveletv1alpha1.Wavelet, idx int32, step sweepStep) (reconcile.Result, error) {
+	if !isClusterAtStep(sweep, cluster, step) {
+		cluster.Spec.Size = step.size
+
+		if sweep.Spec.SnowballK != nil {
+			cluster.Spec.Consensus.SnowballK = step.snowballK
+		}
+
+		if err := r.client.Update(context.TODO(), cluster); err != nil {
+			// The cluster rejects the step (i.e. as the size of the step is smaller than its number of seeds), which
+			// no amount of retrying would change.
+			if errors.IsInvalid(err) || errors.IsForbidden(err) {
+				r.finish(sweep, waveletv1alpha1.WaveletSweepFailed, "InvalidStep", fmt.Sprintf("Cluster %q may not be reconfigured to step %d of %d with size %d and snowball_k %d: %v", cluster.Name, idx+1, sweep.Status.Steps, step.size, step.snowballK, err))
+				return reconcile.Result{}, nil
+			}
+
+			logger.Error(err, "Failed to reconfigure the cluster.", "step", idx)
+			return reconcile.Result{}, err
+		}
+
+		logger.Info("Reconfigured the cluster.", "step", idx, "size", step.size, "snowball_k", step.snowballK)
+
+		sweep.Status.ConvergedTime = nil
+
+		setPhase(sweep, waveletv1alpha1.WaveletSweepConverging, "Converging", fmt.Sprintf("Waiting for the cluster to converge to size %d and snowball_k %d.", step.size, step.snowballK))
+
+		return reconcile.Result{}, nil
+	}
+
+	if !isClusterConverged(cluster) {
+		sweep.Status.ConvergedTime = nil
+
+		setPhase(sweep, waveletv1alpha1.WaveletSweepConverging, "Converging", fmt.Sprintf("Waiting for the cluster to converge to size %d and snowball_k %d.", step.size, step.snowballK))
+
+		return reconcile.Result{}, nil
+	}
+
+	if sweep.Status.ConvergedTime == nil {
+		now := metav1.Now()
+		sweep.Status.ConvergedTime = &now
+	}
+
+	if window := sweep.Spec.StabilizationWindow; window != nil {
+		if remaining := time.Until(sweep.Status.ConvergedTime.Add(window.Duration)); remaining > 0 {
+			setPhase(sweep, waveletv1alpha1.WaveletSweepConverging, "Stabilizing", fmt.Sprintf("Letting the cluster settle for %s before benchmarking it.", window.Duration))
+			return reconcile.Result{RequeueAfter: remaining}, nil
+		}
+	}
+
+	benchmark := getSweepBenchmark(sweep, idx, step)
+
+	if err := controllerutil.SetControllerReference(sweep, benchmark, r.scheme); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := r.client.Create(context.TODO(), benchmark); err != nil && !errors.IsAlreadyExists(err) {
+		logger.Error(err, "Failed to launch the benchmark of the step.", "step", idx)
+		return reconcile.Result{}, err
+	}
+
+	logger.Info("Launched the benchmark of the step.", "step", idx, "benchmark", benchmark.Name)
+
+	setPhase(sweep, waveletv1alpha1.WaveletSweepBenchmarking, "Benchmarking", fmt.Sprintf("Benchmarking step %d of %d with size %d and snowball_k %d.", idx+1, sweep.Status.Steps, step.size, step.snowballK))
+
+	return reconcile.Result{}, nil
+}
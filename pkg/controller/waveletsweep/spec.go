@@ -0,0 +1,211 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package waveletsweep
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"github.com/perlin-network/wavelet-operator/pkg/controller/wavelet"
+	"k8s.io/apimachinery/pkg/labels"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TableKey is the key of the table ConfigMap of a sweep holding its results as CSV.
+const TableKey = "results.csv"
+
+// sweepStep is the configuration of a cluster at a single step of a sweep.
+type sweepStep struct {
+	size      int32
+	snowballK int32
+}
+
+func labelsForSweep(sweep *waveletv1alpha1.WaveletSweep, role string) labels.Set {
+	return labels.Set{"sweep": sweep.Name, "role": role}
+}
+
+// getSweepValues returns all values a parameter is swept through, or only the current value of the parameter should
+// it not be swept through.
+func getSweepValues(param *waveletv1alpha1.WaveletSweepParameter, current int32) []int32 {
+	if param == nil {
+		return []int32{current}
+	}
+
+	if len(param.Values) > 0 {
+		return param.Values
+	}
+
+	from, step := param.From, param.Step
+
+	if from == 0 {
+		from = 1
+	}
+
+	if step == 0 {
+		step = 1
+	}
+
+	var values []int32
+
+	for value := from; value <= param.To; value += step {
+		values = append(values, value)
+	}
+
+	return values
+}
+
+func getClusterSnowballK(cluster *waveletv1alpha1.Wavelet) int32 {
+	if cluster.Spec.Consensus.SnowballK > 0 {
+		return cluster.Spec.Consensus.SnowballK
+	}
+
+	return wavelet.DefaultSnowballK
+}
+
+// getSweepSteps returns every step of a sweep in the order they are walked through. Parameters that are not swept
+// through are held at their current value in the cluster.
+func getSweepSteps(sweep *waveletv1alpha1.WaveletSweep, cluster *waveletv1alpha1.Wavelet) []sweepStep {
+	var steps []sweepStep
+
+	for _, size := range getSweepValues(sweep.Spec.Size, cluster.Spec.Size) {
+		for _, snowballK := range getSweepValues(sweep.Spec.SnowballK, getClusterSnowballK(cluster)) {
+			steps = append(steps, sweepStep{size: size, snowballK: snowballK})
+		}
+	}
+
+	return steps
+}
+
+// isClusterAtStep reports whether the spec of a cluster matches a step of a sweep.
+func isClusterAtStep(sweep *waveletv1alpha1.WaveletSweep, cluster *waveletv1alpha1.Wavelet, step sweepStep) bool {
+	if cluster.Spec.Size != step.size {
+		return false
+	}
+
+	return sweep.Spec.SnowballK == nil || getClusterSnowballK(cluster) == step.snowballK
+}
+
+// isClusterConverged reports whether every node of a cluster is ready and healthy, and runs with the latest spec of
+// the cluster.
+func isClusterConverged(cluster *waveletv1alpha1.Wavelet) bool {
+	status := cluster.Status
+
+	if status.ObservedGeneration != cluster.Generation {
+		return false
+	}
+
+	if status.Phase != waveletv1alpha1.WaveletPhaseReady && status.Phase != waveletv1alpha1.WaveletPhaseBenchmarking {
+		return false
+	}
+
+	return status.ReadyNodes == cluster.Spec.Size && status.UpdatedNodes == cluster.Spec.Size
+}
+
+func getSweepBenchmarkName(sweep *waveletv1alpha1.WaveletSweep, idx int32) string {
+	return fmt.Sprintf("%s-step-%d", sweep.Name, idx)
+}
+
+// getSweepBenchmark returns the benchmark run against the cluster at a given step of a sweep.
+func getSweepBenchmark(sweep *waveletv1alpha1.WaveletSweep, idx int32, step sweepStep) *waveletv1alpha1.WaveletBenchmark {
+	template := sweep.Spec.Benchmark.DeepCopy()
+
+	workers := template.Workers
+
+	if workers == 0 {
+		workers = step.size
+	}
+
+	return &waveletv1alpha1.WaveletBenchmark{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getSweepBenchmarkName(sweep, idx),
+			Namespace: sweep.Namespace,
+			Labels:    labelsForSweep(sweep, "benchmark"),
+		},
+		Spec: waveletv1alpha1.WaveletBenchmarkSpec{
+			Cluster:      sweep.Spec.Cluster,
+			Workers:      workers,
+			TargetTPS:    template.TargetTPS,
+			Duration:     &template.Duration,
+			Transactions: template.Transactions,
+			Image:        template.Image,
+			Target:       template.Target,
+		},
+	}
+}
+
+func getSweepTableName(sweep *waveletv1alpha1.WaveletSweep) string {
+	return fmt.Sprintf("%s-results", sweep.Name)
+}
+
+// getSweepTable renders the results of every step of a sweep benchmarked so far as CSV.
+func getSweepTable(sweep *waveletv1alpha1.WaveletSweep) (string, error) {
+	var buf bytes.Buffer
+
+	w := csv.NewWriter(&buf)
+
+	rows := [][]string{{"step", "size", "snowball_k", "benchmark", "phase", "tps", "accepted", "errors", "p50", "p90", "p99", "max"}}
+
+	for _, result := range sweep.Status.Results {
+		row := []string{
+			strconv.Itoa(int(result.Step)),
+			strconv.Itoa(int(result.Size)),
+			strconv.Itoa(int(result.SnowballK)),
+			result.Benchmark,
+			string(result.Phase),
+		}
+
+		if r := result.Results; r != nil {
+			row = append(row,
+				r.TPS,
+				strconv.FormatUint(r.Accepted, 10),
+				strconv.FormatUint(r.Errors, 10),
+				r.Latency.P50.Duration.String(),
+				r.Latency.P90.Duration.String(),
+				r.Latency.P99.Duration.String(),
+				r.Latency.Max.Duration.String(),
+			)
+		} else {
+			row = append(row, "", "", "", "", "", "", "")
+		}
+
+		rows = append(rows, row)
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+func getSweepTableConfigMap(sweep *waveletv1alpha1.WaveletSweep, table string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getSweepTableName(sweep),
+			Namespace: sweep.Namespace,
+			Labels:    labelsForSweep(sweep, "results"),
+		},
+		Data: map[string]string{TableKey: table},
+	}
+}
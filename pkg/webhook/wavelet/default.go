@@ -0,0 +1,78 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"context"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"github.com/perlin-network/wavelet-operator/pkg/controller/wavelet"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+var _ admission.Handler = &waveletDefaulter{}
+
+// waveletDefaulter fills in the parameters a Wavelet resource leaves unset with the defaults the controller would
+// otherwise assume, such that the parameters a cluster is run with are visible on the resource itself.
+type waveletDefaulter struct {
+	decoder types.Decoder
+}
+
+func (d *waveletDefaulter) InjectDecoder(decoder types.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+func (d *waveletDefaulter) Handle(ctx context.Context, req types.Request) types.Response {
+	cluster := new(waveletv1alpha1.Wavelet)
+
+	if err := d.decoder.Decode(req, cluster); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	defaulted := cluster.DeepCopy()
+	setWaveletDefaults(defaulted)
+
+	return admission.PatchResponse(cluster, defaulted)
+}
+
+func setWaveletDefaults(cluster *waveletv1alpha1.Wavelet) {
+	if cluster.Spec.Consensus.SnowballK == 0 {
+		cluster.Spec.Consensus.SnowballK = wavelet.DefaultSnowballK
+	}
+
+	if cluster.Spec.Consensus.SnowballBeta == 0 {
+		cluster.Spec.Consensus.SnowballBeta = wavelet.DefaultSnowballBeta
+	}
+
+	if cluster.Spec.UpdateStrategy.MaxUnavailable == 0 {
+		cluster.Spec.UpdateStrategy.MaxUnavailable = wavelet.DefaultMaxUnavailable
+	}
+
+	if cluster.Spec.UpdateStrategy.BootstrapOrder == "" {
+		cluster.Spec.UpdateStrategy.BootstrapOrder = waveletv1alpha1.WaveletBootstrapLast
+	}
+
+	if cluster.Spec.Storage != nil && cluster.Spec.Storage.RetentionPolicy == "" {
+		cluster.Spec.Storage.RetentionPolicy = waveletv1alpha1.WaveletStorageRetain
+	}
+}
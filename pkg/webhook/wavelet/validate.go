@@ -0,0 +1,287 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/perlin-network/noise/edwards25519"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"github.com/perlin-network/wavelet-operator/pkg/controller/wavelet"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+var _ admission.Handler = &waveletValidator{}
+
+// waveletValidator rejects Wavelet resources that the controller would be unable to reconcile, and edits to a
+// Wavelet resource that would require the genesis of its cluster to be regenerated.
+type waveletValidator struct {
+	client  client.Client
+	decoder types.Decoder
+}
+
+func (v *waveletValidator) InjectClient(c client.Client) error {
+	v.client = c
+	return nil
+}
+
+func (v *waveletValidator) InjectDecoder(decoder types.Decoder) error {
+	v.decoder = decoder
+	return nil
+}
+
+func (v *waveletValidator) Handle(ctx context.Context, req types.Request) types.Response {
+	cluster := new(waveletv1alpha1.Wavelet)
+
+	if err := v.decoder.Decode(req, cluster); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	// Clusters being torn down are only ever updated to release their finalizers, which must not be blocked.
+	if cluster.GetDeletionTimestamp() != nil {
+		return admission.ValidationResponse(true, "")
+	}
+
+	problems := validateWavelet(cluster)
+
+	if req.AdmissionRequest.Operation == admissionv1beta1.Update {
+		old := new(waveletv1alpha1.Wavelet)
+
+		if err := json.Unmarshal(req.AdmissionRequest.OldObject.Raw, old); err != nil {
+			return admission.ErrorResponse(http.StatusBadRequest, err)
+		}
+
+		problems = append(problems, validateWaveletUpdate(old, cluster)...)
+	}
+
+	walletProblems, err := v.validateWaveletWallets(ctx, cluster)
+
+	if err != nil {
+		log.Error(err, "Failed to validate the wallets of a cluster.", "namespace", cluster.Namespace, "name", cluster.Name)
+		return admission.ErrorResponse(http.StatusInternalServerError, err)
+	}
+
+	problems = append(problems, walletProblems...)
+
+	if len(problems) > 0 {
+		return admission.ValidationResponse(false, strings.Join(problems, "; "))
+	}
+
+	return admission.ValidationResponse(true, "")
+}
+
+// validateWavelet checks the spec of a cluster for parameters that are out of range, or inconsistent with one
+// another.
+func validateWavelet(cluster *waveletv1alpha1.Wavelet) []string {
+	var problems []string
+
+	spec := cluster.Spec
+
+	if spec.Size < 0 {
+		problems = append(problems, "size must not be negative")
+	}
+
+	// Seeds beyond the size of the cluster would never come up, and fail to resolve for as long as they are down.
+	if spec.Size > 0 && spec.NumSeeds > spec.Size {
+		problems = append(problems, fmt.Sprintf("num_seeds (%d) must not exceed size (%d)", spec.NumSeeds, spec.Size))
+	}
+
+	problems = append(problems, ValidateBenchmarkTarget("benchmark_target", spec.BenchmarkTarget)...)
+
+	if spec.MemoryMax < 0 {
+		problems = append(problems, "memory_max must not be negative")
+	}
+
+	if spec.Consensus.SnowballK < 0 {
+		problems = append(problems, "consensus.snowball_k must not be negative")
+	}
+
+	if spec.Consensus.SnowballBeta < 0 {
+		problems = append(problems, "consensus.snowball_beta must not be negative")
+	}
+
+	if alpha := spec.Consensus.SnowballAlpha; len(alpha) > 0 {
+		if f, err := strconv.ParseFloat(alpha, 64); err != nil || f <= 0 || f > 1 {
+			problems = append(problems, fmt.Sprintf("consensus.snowball_alpha (%q) must be a number in (0, 1]", alpha))
+		}
+	}
+
+	if spec.Puzzle.C1 < 0 || spec.Puzzle.C1 > 32 || spec.Puzzle.C2 < 0 || spec.Puzzle.C2 > 32 {
+		problems = append(problems, "puzzle.c1 and puzzle.c2 must be within [1, 32]")
+	}
+
+	if spec.UpdateStrategy.MaxUnavailable < 0 {
+		problems = append(problems, "update_strategy.max_unavailable must not be negative")
+	}
+
+	if spec.Storage != nil && spec.Storage.Size.Sign() <= 0 {
+		problems = append(problems, "storage.size must be positive")
+	}
+
+	if archive := spec.Archive; archive != nil {
+		if (len(archive.PersistentVolumeClaim) > 0) == (archive.S3 != nil) {
+			problems = append(problems, "exactly one of archive.persistent_volume_claim and archive.s3 must be set")
+		}
+
+		if s3 := archive.S3; s3 != nil {
+			if !strings.HasPrefix(s3.Endpoint, "http://") && !strings.HasPrefix(s3.Endpoint, "https://") {
+				problems = append(problems, fmt.Sprintf("archive.s3.endpoint (%q) must be an http:// or https:// URL", s3.Endpoint))
+			}
+
+			if len(s3.Bucket) == 0 || len(s3.CredentialsSecret) == 0 {
+				problems = append(problems, "archive.s3.bucket and archive.s3.credentials_secret must be set")
+			}
+		}
+	}
+
+	if genesis := spec.Genesis; genesis != nil {
+		publicKeys := make(map[string]int, len(genesis.Accounts))
+
+		for i, account := range genesis.Accounts {
+			if len(account.PublicKey) == 0 {
+				continue
+			}
+
+			if _, err := hex.DecodeString(account.PublicKey); err != nil || len(account.PublicKey) != hex.EncodedLen(edwards25519.SizePublicKey) {
+				problems = append(problems, fmt.Sprintf("genesis.accounts[%d].public_key must be a hex-encoded public key", i))
+			}
+
+			if j, exists := publicKeys[account.PublicKey]; exists {
+				problems = append(problems, fmt.Sprintf("genesis.accounts[%d] allocates the same public key as genesis.accounts[%d]", i, j))
+			}
+
+			publicKeys[account.PublicKey] = i
+		}
+
+		if genesis.ConfigMap != nil && len(genesis.ConfigMap.Name) == 0 {
+			problems = append(problems, "genesis.config_map.name must be set")
+		}
+	}
+
+	for i, env := range spec.ExtraEnv {
+		if len(env.Name) == 0 {
+			problems = append(problems, fmt.Sprintf("extra_env[%d] must have a name", i))
+		}
+	}
+
+	return problems
+}
+
+// ValidateBenchmarkTarget checks that the selector of a benchmark target is set if and only if its strategy is
+// Selector, and that it parses. Problems are reported against the field at path.
+func ValidateBenchmarkTarget(path string, target *waveletv1alpha1.WaveletBenchmarkTargetSpec) []string {
+	if target == nil {
+		return nil
+	}
+
+	var problems []string
+
+	if (target.Strategy == waveletv1alpha1.WaveletBenchmarkTargetSelector) != (target.Selector != nil) {
+		problems = append(problems, fmt.Sprintf("%[1]s.selector must be set if and only if %[1]s.strategy is Selector", path))
+	}
+
+	if target.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(target.Selector); err != nil {
+			problems = append(problems, fmt.Sprintf("%s.selector is invalid: %v", path, err))
+		}
+	}
+
+	return problems
+}
+
+// validateWaveletUpdate rejects edits to parameters that were baked into the genesis of a cluster when it was first
+// created.
+func validateWaveletUpdate(old, cluster *waveletv1alpha1.Wavelet) []string {
+	var problems []string
+
+	if old.Spec.NumRichWallets != cluster.Spec.NumRichWallets {
+		problems = append(problems, fmt.Sprintf("num_rich_wallets may not be changed from %d once a cluster is created", old.Spec.NumRichWallets))
+	}
+
+	if old.Spec.NodeBalance != cluster.Spec.NodeBalance {
+		problems = append(problems, "node_balance may not be changed once a cluster is created")
+	}
+
+	if old.Spec.Puzzle != cluster.Spec.Puzzle {
+		problems = append(problems, "puzzle may not be changed once a cluster is created")
+	}
+
+	if old.Spec.WalletSeed != cluster.Spec.WalletSeed {
+		problems = append(problems, "wallet_seed may not be changed once a cluster is created")
+	}
+
+	if !reflect.DeepEqual(old.Spec.Genesis, cluster.Spec.Genesis) {
+		problems = append(problems, "genesis may not be changed once a cluster is created")
+	}
+
+	return problems
+}
+
+// validateWaveletWallets checks that num_rich_wallets agrees with the number of wallets already generated in the
+// wallet secret of a cluster, should it exist. The controller reuses an existing wallet secret as-is, so a mismatch
+// would otherwise go unnoticed.
+func (v *waveletValidator) validateWaveletWallets(ctx context.Context, cluster *waveletv1alpha1.Wavelet) ([]string, error) {
+	secret := new(corev1.Secret)
+
+	err := v.client.Get(ctx, apitypes.NamespacedName{Namespace: cluster.Namespace, Name: wavelet.GetWaveletWalletSecretName(cluster)}, secret)
+
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var existing uint
+
+	for key := range secret.Data {
+		if strings.HasPrefix(key, wavelet.SecretKeyWalletPrefix) {
+			existing++
+		}
+	}
+
+	// One wallet is included in the genesis of every cluster by default, and is not stored in the wallet secret.
+	expected := uint(0)
+
+	if cluster.Spec.NumRichWallets > 0 {
+		expected = cluster.Spec.NumRichWallets - 1
+	}
+
+	if existing != expected {
+		return []string{fmt.Sprintf("num_rich_wallets implies %d generated wallets, but secret %q already holds %d", expected, secret.Name, existing)}, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package wavelet
+
+import (
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/builder"
+)
+
+var log = logf.Log.WithName("wavelet.webhook")
+
+// Add builds a mutating webhook that defaults Wavelet resources, and a validating webhook that rejects Wavelet
+// resources the controller would be unable to reconcile.
+func Add(mgr manager.Manager) ([]webhook.Webhook, error) {
+	mutating, err := builder.NewWebhookBuilder().
+		Name("mutating.wavelet.perlin.net").
+		Mutating().
+		Path("/mutate-wavelet").
+		Operations(admissionregistrationv1beta1.Create, admissionregistrationv1beta1.Update).
+		FailurePolicy(admissionregistrationv1beta1.Fail).
+		ForType(new(waveletv1alpha1.Wavelet)).
+		WithManager(mgr).
+		Handlers(new(waveletDefaulter)).
+		Build()
+
+	if err != nil {
+		return nil, err
+	}
+
+	validating, err := builder.NewWebhookBuilder().
+		Name("validating.wavelet.perlin.net").
+		Validating().
+		Path("/validate-wavelet").
+		Operations(admissionregistrationv1beta1.Create, admissionregistrationv1beta1.Update).
+		FailurePolicy(admissionregistrationv1beta1.Fail).
+		ForType(new(waveletv1alpha1.Wavelet)).
+		WithManager(mgr).
+		Handlers(new(waveletValidator)).
+		Build()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []webhook.Webhook{mutating, validating}, nil
+}
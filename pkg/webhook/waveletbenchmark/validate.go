@@ -0,0 +1,80 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package waveletbenchmark
+
+import (
+	"context"
+	"fmt"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"github.com/perlin-network/wavelet-operator/pkg/controller/waveletbenchmark"
+	"github.com/perlin-network/wavelet-operator/pkg/webhook/wavelet"
+	"net/http"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+var _ admission.Handler = &benchmarkValidator{}
+
+// benchmarkValidator rejects WaveletBenchmark resources whose parameters are inconsistent with one another, or whose
+// schedule is not a valid cron expression. Other checks on a single field are left to the validation schema of the
+// CRD.
+type benchmarkValidator struct {
+	decoder types.Decoder
+}
+
+func (v *benchmarkValidator) InjectDecoder(decoder types.Decoder) error {
+	v.decoder = decoder
+	return nil
+}
+
+func (v *benchmarkValidator) Handle(ctx context.Context, req types.Request) types.Response {
+	benchmark := new(waveletv1alpha1.WaveletBenchmark)
+
+	if err := v.decoder.Decode(req, benchmark); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	if problems := validateBenchmark(benchmark); len(problems) > 0 {
+		return admission.ValidationResponse(false, strings.Join(problems, "; "))
+	}
+
+	return admission.ValidationResponse(true, "")
+}
+
+func validateBenchmark(benchmark *waveletv1alpha1.WaveletBenchmark) []string {
+	spec := benchmark.Spec
+
+	problems := wavelet.ValidateBenchmarkTarget("target", spec.Target)
+
+	// The target TPS is split evenly between workers, which would otherwise each be asked to submit 0 transactions per
+	// second (i.e. as fast as they can).
+	if spec.TargetTPS > 0 && int64(spec.TargetTPS) < int64(spec.Workers) {
+		problems = append(problems, fmt.Sprintf("target_tps (%d) must be at least workers (%d)", spec.TargetTPS, spec.Workers))
+	}
+
+	if len(spec.Schedule) > 0 {
+		if err := waveletbenchmark.ValidateSchedule(spec.Schedule); err != nil {
+			problems = append(problems, fmt.Sprintf("schedule %q is invalid: %v", spec.Schedule, err))
+		}
+	}
+
+	return problems
+}
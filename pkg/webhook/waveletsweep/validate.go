@@ -0,0 +1,200 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+package waveletsweep
+
+import (
+	"context"
+	"fmt"
+	waveletv1alpha1 "github.com/perlin-network/wavelet-operator/pkg/apis/wavelet/v1alpha1"
+	"github.com/perlin-network/wavelet-operator/pkg/webhook/wavelet"
+	"net/http"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+var _ admission.Handler = &sweepValidator{}
+
+// sweepValidator rejects WaveletSweep resources whose parameters are inconsistent with one another, including those
+// that would have the sweep create benchmarks rejected by the WaveletBenchmark webhook, or reconfigure its cluster
+// into a spec rejected by the Wavelet webhook. Checks on a single field are left to the validation schema of the CRD.
+type sweepValidator struct {
+	client  client.Client
+	decoder types.Decoder
+}
+
+func (v *sweepValidator) InjectClient(c client.Client) error {
+	v.client = c
+	return nil
+}
+
+func (v *sweepValidator) InjectDecoder(decoder types.Decoder) error {
+	v.decoder = decoder
+	return nil
+}
+
+func (v *sweepValidator) Handle(ctx context.Context, req types.Request) types.Response {
+	sweep := new(waveletv1alpha1.WaveletSweep)
+
+	if err := v.decoder.Decode(req, sweep); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	problems := validateSweep(sweep)
+
+	// Stopping a sweep must not be blocked by changes made to its cluster since the sweep was created.
+	if !sweep.Spec.Stop && sweep.GetDeletionTimestamp() == nil {
+		cluster := new(waveletv1alpha1.Wavelet)
+
+		err := v.client.Get(ctx, apitypes.NamespacedName{Namespace: sweep.Namespace, Name: sweep.Spec.Cluster}, cluster)
+
+		switch {
+		case err == nil:
+			problems = append(problems, validateSweepCluster(sweep, cluster)...)
+		case !errors.IsNotFound(err):
+			log.Error(err, "Failed to query the cluster of a sweep.", "namespace", sweep.Namespace, "name", sweep.Name, "cluster", sweep.Spec.Cluster)
+			return admission.ErrorResponse(http.StatusInternalServerError, err)
+		}
+	}
+
+	if len(problems) > 0 {
+		return admission.ValidationResponse(false, strings.Join(problems, "; "))
+	}
+
+	return admission.ValidationResponse(true, "")
+}
+
+func validateSweep(sweep *waveletv1alpha1.WaveletSweep) []string {
+	spec := sweep.Spec
+
+	problems := validateSweepParameter("size", spec.Size)
+	problems = append(problems, validateSweepParameter("snowball_k", spec.SnowballK)...)
+	problems = append(problems, wavelet.ValidateBenchmarkTarget("benchmark.target", spec.Benchmark.Target)...)
+
+	if tps := spec.Benchmark.TargetTPS; tps > 0 {
+		// Benchmarks run one worker per node should workers be left unset, of which there are the most at the
+		// largest size swept through. The size of the cluster is not known here should it not be swept through.
+		workers := spec.Benchmark.Workers
+
+		if workers == 0 {
+			workers = getLargestSweepValue(spec.Size)
+		}
+
+		if int64(tps) < int64(workers) {
+			problems = append(problems, fmt.Sprintf("benchmark.target_tps (%d) must be at least the number of workers of every benchmark (%d)", tps, workers))
+		}
+	}
+
+	return problems
+}
+
+// validateSweepCluster checks that the cluster of a sweep accepts every size the sweep would scale it to. Clusters
+// may not have more seeds than nodes, save for clusters scaled to 0 which run no nodes at all. Sweeps of clusters that
+// do not exist yet are left to fail once reconciled.
+func validateSweepCluster(sweep *waveletv1alpha1.WaveletSweep, cluster *waveletv1alpha1.Wavelet) []string {
+	if smallest := getSmallestSweepValue(sweep.Spec.Size); smallest > 0 && cluster.Spec.NumSeeds > smallest {
+		return []string{fmt.Sprintf("size must not be swept below the num_seeds (%d) of cluster %q, but is swept down to %d", cluster.Spec.NumSeeds, cluster.Name, smallest)}
+	}
+
+	return nil
+}
+
+// validateSweepParameter checks that a parameter lists its values either explicitly or as a non-empty range.
+func validateSweepParameter(path string, param *waveletv1alpha1.WaveletSweepParameter) []string {
+	if param == nil {
+		return nil
+	}
+
+	var problems []string
+
+	if (len(param.Values) > 0) == (param.To > 0) {
+		problems = append(problems, fmt.Sprintf("exactly one of %[1]s.values and %[1]s.to must be set", path))
+	}
+
+	if param.To > 0 && param.From > param.To {
+		problems = append(problems, fmt.Sprintf("%[1]s.from (%[2]d) must not exceed %[1]s.to (%[3]d)", path, param.From, param.To))
+	}
+
+	return problems
+}
+
+// getLargestSweepValue returns the largest value a parameter is swept through, or 0 should it not be swept through.
+func getLargestSweepValue(param *waveletv1alpha1.WaveletSweepParameter) int32 {
+	if param == nil {
+		return 0
+	}
+
+	if len(param.Values) == 0 {
+		from, step := param.From, param.Step
+
+		if from == 0 {
+			from = 1
+		}
+
+		if step == 0 {
+			step = 1
+		}
+
+		if param.To < from {
+			return 0
+		}
+
+		return from + (param.To-from)/step*step
+	}
+
+	largest := param.Values[0]
+
+	for _, value := range param.Values[1:] {
+		if value > largest {
+			largest = value
+		}
+	}
+
+	return largest
+}
+
+// getSmallestSweepValue returns the smallest non-zero value a parameter is swept through, or 0 should it not be swept
+// through.
+func getSmallestSweepValue(param *waveletv1alpha1.WaveletSweepParameter) int32 {
+	if param == nil {
+		return 0
+	}
+
+	if len(param.Values) == 0 {
+		if param.From == 0 {
+			return 1
+		}
+
+		return param.From
+	}
+
+	var smallest int32
+
+	for _, value := range param.Values {
+		if value > 0 && (smallest == 0 || value < smallest) {
+			smallest = value
+		}
+	}
+
+	return smallest
+}
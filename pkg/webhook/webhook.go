@@ -0,0 +1,58 @@
+// Copyright (c) 2019 Perlin
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package webhook
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// AddToServerFuncs is a list of functions that build admission webhooks to be served by the webhook server of a
+// manager.
+var AddToServerFuncs []func(manager.Manager) ([]webhook.Webhook, error)
+
+// AddToManager creates a webhook server serving all webhooks built by AddToServerFuncs, and adds it to the Manager.
+// The server provisions its own certificate, and installs the webhook configurations and Service needed for the API
+// server to reach it.
+func AddToManager(m manager.Manager, options webhook.ServerOptions) error {
+	var webhooks []webhook.Webhook
+
+	for _, f := range AddToServerFuncs {
+		w, err := f(m)
+
+		if err != nil {
+			return err
+		}
+
+		webhooks = append(webhooks, w...)
+	}
+
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	server, err := webhook.NewServer("wavelet-operator-webhook", m, options)
+
+	if err != nil {
+		return err
+	}
+
+	return server.Register(webhooks...)
+}